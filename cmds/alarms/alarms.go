@@ -0,0 +1,197 @@
+package alarms
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// DefaultCritical is the types where a disabled alarm link is an issue
+var DefaultCritical = []string{"InfinityInput", "InfinityOutput"}
+
+// issues found in the alarm links
+const (
+	issueMissing  = "missing enrollment"
+	issueDisabled = "disabled on critical type"
+)
+
+// skipFields are the enrollment properties not listed by default, they
+// are already in the report or are blocks of text.
+var skipFields = []string{"Name", "Type", "Alias", "DeviceId", "AlarmLinks", "ByteCode", "LastChange"}
+
+// link is an alarm link slot of a point
+type link struct {
+	point      *dmp.Object
+	alarm      *dmp.AlarmLink
+	enrollment *dmp.Object
+	issue      string
+}
+
+type Command struct {
+	FileName string
+	OutFile  string
+	Format   string
+	SheetBy  string
+	Fields   []string
+	Critical []string
+	Issues   bool
+}
+
+func (cmd *Command) Execute() {
+
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	x := dmp.NewIndex()
+	dmpPath := dmp.ParseFile(cmd.FileName, x)
+
+	critical := cmd.Critical
+	if len(critical) == 0 {
+		critical = DefaultCritical
+	}
+
+	links := findLinks(x, critical)
+	if cmd.Issues {
+		links = slices.DeleteFunc(links, func(l *link) bool {
+			return l.issue == ""
+		})
+	}
+
+	if len(links) == 0 {
+		fmt.Println("No alarm links found.")
+		return
+	}
+
+	fields := cmd.Fields
+	if len(fields) == 0 {
+		fields = enrollmentFields(links)
+	}
+	table := linkTable(links, fields)
+	table.SheetBy = cmd.SheetBy
+
+	err = output.Create(cmd.OutFile, func(w io.Writer) error {
+		if format == output.Text {
+			writeTitle(w, dmpPath, links)
+		}
+		return output.Write(w, format, table)
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// findLinks returns the alarm link slots of all the points in the order
+// of the dump, with the enrollments resolved and the issues flagged.
+func findLinks(x *dmp.Index, critical []string) []*link {
+	links := make([]*link, 0)
+	for _, obj := range x.Objects() {
+		s, ok := obj.Properties["AlarmLinks"]
+		if !ok {
+			continue
+		}
+		for _, alarm := range dmp.ParseAlarmLinks(s) {
+			if alarm == nil {
+				continue
+			}
+			l := &link{
+				point:      obj,
+				alarm:      alarm,
+				enrollment: x.Resolve(alarm.Path, obj.Path),
+			}
+			switch {
+			case l.enrollment == nil:
+				l.issue = issueMissing
+			case !alarm.Enabled && slices.Contains(critical, obj.Type):
+				l.issue = issueDisabled
+			}
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// enrollmentFields returns the single line properties of the
+// enrollments in the order they are first found.
+func enrollmentFields(links []*link) []string {
+	fields := make([]string, 0)
+	seen := make(map[*dmp.Object]bool)
+	for _, l := range links {
+		e := l.enrollment
+		if e == nil || seen[e] {
+			continue
+		}
+		seen[e] = true
+		names := make([]string, 0, len(e.Properties))
+		for k, v := range e.Properties {
+			if strings.Contains(v, "\n") || slices.Contains(skipFields, k) || slices.Contains(fields, k) {
+				continue
+			}
+			names = append(names, k)
+		}
+		slices.Sort(names)
+		fields = append(fields, names...)
+	}
+	return fields
+}
+
+func enabled(b bool) string {
+	if b {
+		return "Enabled"
+	}
+	return "Disabled"
+}
+
+func linkTable(links []*link, fields []string) *output.Table {
+	table := &output.Table{
+		Header: append([]string{"Point", "Type", "Slot", "Enrollment", "Enabled", "Enrollment Type"}, fields...),
+		Rows:   make([][]string, 0, len(links)),
+	}
+	table.Header = append(table.Header, "Issue")
+
+	for _, l := range links {
+		row := []string{
+			l.point.Path,
+			l.point.Type,
+			strconv.Itoa(l.alarm.Id),
+			l.alarm.Path,
+			enabled(l.alarm.Enabled),
+			"",
+		}
+		if l.enrollment != nil {
+			row[5] = l.enrollment.Type
+		}
+		for _, f := range fields {
+			v := ""
+			if l.enrollment != nil {
+				v = l.enrollment.Properties[f]
+			}
+			row = append(row, v)
+		}
+		row = append(row, l.issue)
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// writeTitle writes the title and the count of issues for the text output
+func writeTitle(w io.Writer, dmpPath string, links []*link) {
+	missing, disabled := 0, 0
+	for _, l := range links {
+		switch l.issue {
+		case issueMissing:
+			missing++
+		case issueDisabled:
+			disabled++
+		}
+	}
+	fmt.Fprintf(w, "Alarm links\n\n  Source device: %s\n  Links: %d\n  Missing enrollments: %d\n  Disabled on critical types: %d\n\n",
+		dmpPath, len(links), missing, disabled)
+}
@@ -0,0 +1,74 @@
+package alarms
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const alarmDump = `Path : NUSite
+BeginController : Ctrl1
+Object : SAT
+Type : InfinityInput
+AlarmLinks
+  NUSite\Ctrl1\HighAlarm : 1 : Enabled
+  NUSite\Ctrl1\LowAlarm : 2 : Disabled
+  NUSite\Ctrl1\Gone : 3 : Enabled
+EndAlarmLinks
+EndObject
+Object : Setpt
+Type : InfinityNumeric
+AlarmLinks
+  NUSite\Ctrl1\LowAlarm : 1 : Disabled
+EndAlarmLinks
+EndObject
+Object : HighAlarm
+Type : AlarmEnrollment
+HighLimit : 90
+Delay : 30
+EndObject
+Object : LowAlarm
+Type : AlarmEnrollment
+LowLimit : 40
+EndObject
+EndController
+`
+
+func TestFindLinks(t *testing.T) {
+	x := dmp.NewIndex()
+	dmp.Parse(strings.NewReader(alarmDump), x)
+
+	links := findLinks(x, DefaultCritical)
+
+	expected := []struct {
+		point string
+		slot  int
+		issue string
+	}{
+		{"SAT", 1, ""},
+		{"SAT", 2, issueDisabled},
+		{"SAT", 3, issueMissing},
+		{"Setpt", 1, ""},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("expected %d links got %d", len(expected), len(links))
+	}
+	for i, e := range expected {
+		l := links[i]
+		if l.point.Name != e.point || l.alarm.Id != e.slot || l.issue != e.issue {
+			t.Errorf("expected %v got %s %d %q", e, l.point.Name, l.alarm.Id, l.issue)
+		}
+	}
+
+	fields := enrollmentFields(links)
+	if !slices.Equal(fields, []string{"Delay", "HighLimit", "LowLimit"}) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+
+	table := linkTable(links, fields)
+	if got := table.Rows[0][table.Column("HighLimit")]; got != "90" {
+		t.Errorf("expected HighLimit 90 got %q", got)
+	}
+}
@@ -0,0 +1,319 @@
+package calls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/digraph"
+	"github.com/tpacheco/dmptool/internal/output"
+	"github.com/tpacheco/dmptool/pe"
+)
+
+// graph output formats
+const (
+	GraphTree = "tree"
+	GraphDot  = "dot"
+)
+
+var ErrUnknownGraph = errors.New("unknown graph format")
+
+// issues found in the calls
+const (
+	issueUnused    = "unused function"
+	issueRecursion = "recursion"
+	issueArgs      = "wrong argument count"
+	issueSyntax    = "syntax error"
+)
+
+// call is a call of a function in the code of a program or function
+type call struct {
+	caller *dmp.Object
+	callee *dmp.Object
+	pos    pe.Pos
+	args   int
+}
+
+// issue is a problem found in the call graph
+type issue struct {
+	issue  string
+	source *dmp.Object
+	target *dmp.Object
+	line   int
+	detail string
+}
+
+// callGraph is the calls among the programs and functions of a dump
+type callGraph struct {
+	// code is the programs and functions in the order of the dump
+	code  []*dmp.Object
+	calls []*call
+	// args is the number of arguments declared by each function
+	args map[*dmp.Object]int
+	// syntax is the statements that could not be parsed, the calls in
+	// them are not in the graph
+	syntax []*issue
+}
+
+type Command struct {
+	FileName string
+	OutFile  string
+	Graph    string
+	Format   string
+	SheetBy  string
+	Issues   bool
+}
+
+func isFunction(obj *dmp.Object) bool {
+	return obj != nil && obj.Type == "InfinityFunction"
+}
+
+func (cmd *Command) Execute() {
+
+	if cmd.Graph == "" {
+		cmd.Graph = GraphTree
+	}
+	if cmd.Graph != GraphTree && cmd.Graph != GraphDot {
+		fmt.Printf("%s: %s, use %s or %s\n", ErrUnknownGraph, cmd.Graph, GraphTree, GraphDot)
+		return
+	}
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	x := dmp.NewIndex()
+	dmpPath := dmp.ParseFile(cmd.FileName, x)
+	g := buildGraph(x)
+	issues := g.issues()
+
+	err = output.Create(cmd.OutFile, func(w io.Writer) error {
+		switch {
+		case cmd.Issues:
+			if len(issues) == 0 {
+				_, err := io.WriteString(w, "No call issues found.\n")
+				return err
+			}
+			table := issueTable(issues)
+			table.SheetBy = cmd.SheetBy
+			return output.Write(w, format, table)
+		case cmd.Graph == GraphDot:
+			return g.writeDot(w)
+		default:
+			fmt.Fprintf(w, "Calls\n\n  Source device: %s\n  Programs and functions: %d\n  Calls: %d\n  Issues: %d\n\n",
+				dmpPath, len(g.code), len(g.calls), len(issues))
+			g.writeTree(w)
+			if len(issues) > 0 {
+				io.WriteString(w, "\n")
+				return output.Write(w, output.Text, issueTable(issues))
+			}
+			return nil
+		}
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// argCount returns the number of arguments declared by the Arg statements,
+// the highest argument number.
+func argCount(f *pe.File) int {
+	n := 0
+	for _, s := range f.Stmts {
+		if arg, ok := s.(*pe.ArgDecl); ok {
+			i, err := strconv.Atoi(arg.Index.Value)
+			if err != nil {
+				i = n + 1
+			}
+			n = max(n, i)
+		}
+	}
+	return n
+}
+
+// buildGraph finds the calls of the functions in the dump. Calls of names
+// that are not functions of the dump, like the built in functions, are
+// not in the graph, nor the calls in the statements with syntax errors.
+func buildGraph(x *dmp.Index) *callGraph {
+	g := &callGraph{args: make(map[*dmp.Object]int)}
+	for _, obj := range x.Objects() {
+		if !dmp.IsCode(obj.Type) {
+			continue
+		}
+		g.code = append(g.code, obj)
+		f, errs := pe.Parse(obj.Properties["ByteCode"])
+		for _, err := range errs {
+			g.syntax = append(g.syntax, &issue{issue: issueSyntax, source: obj, line: err.Line, detail: err.Msg})
+		}
+		if isFunction(obj) {
+			g.args[obj] = argCount(f)
+		}
+		pe.Inspect(f, func(n pe.Node) bool {
+			c, ok := n.(*pe.Call)
+			if !ok {
+				return true
+			}
+			if target := x.Resolve(c.Func.Name, obj.Path); isFunction(target) {
+				g.calls = append(g.calls, &call{
+					caller: obj,
+					callee: target,
+					pos:    c.Pos,
+					args:   len(c.Args),
+				})
+			}
+			return true
+		})
+	}
+	return g
+}
+
+// callees returns the functions called by the object in the order of the
+// first call.
+func (g *callGraph) callees(obj *dmp.Object) []*dmp.Object {
+	list := make([]*dmp.Object, 0)
+	for _, c := range g.calls {
+		if c.caller == obj && !slices.Contains(list, c.callee) {
+			list = append(list, c.callee)
+		}
+	}
+	return list
+}
+
+func (g *callGraph) isCalled(obj *dmp.Object) bool {
+	return slices.ContainsFunc(g.calls, func(c *call) bool {
+		return c.callee == obj && c.caller != obj
+	})
+}
+
+// issues returns the syntax errors, the unused functions, the functions
+// that call themselves directly or through other functions, and the calls
+// with the wrong number of arguments.
+func (g *callGraph) issues() []*issue {
+	list := slices.Clone(g.syntax)
+	for _, obj := range g.code {
+		if isFunction(obj) && !g.isCalled(obj) {
+			list = append(list, &issue{issue: issueUnused, source: obj, detail: "not called"})
+		}
+	}
+
+	byPath := make(map[string]*dmp.Object)
+	edges := make(map[string][]string)
+	for _, c := range g.calls {
+		byPath[c.caller.Path], byPath[c.callee.Path] = c.caller, c.callee
+		if c.caller == c.callee {
+			list = append(list, &issue{issue: issueRecursion, source: c.caller, target: c.callee, line: c.pos.Line, detail: "calls itself"})
+			continue
+		}
+		if !slices.Contains(edges[c.caller.Path], c.callee.Path) {
+			edges[c.caller.Path] = append(edges[c.caller.Path], c.callee.Path)
+		}
+	}
+	for _, group := range digraph.Cycles(edges) {
+		list = append(list, &issue{issue: issueRecursion, source: byPath[group[0]], detail: strings.Join(group, " -> ")})
+	}
+
+	for _, c := range g.calls {
+		if want := g.args[c.callee]; c.args != want {
+			list = append(list, &issue{
+				issue:  issueArgs,
+				source: c.caller,
+				target: c.callee,
+				line:   c.pos.Line,
+				detail: fmt.Sprintf("%d arguments, %s declares %d", c.args, c.callee.Name, want),
+			})
+		}
+	}
+	return list
+}
+
+func issueTable(issues []*issue) *output.Table {
+	table := &output.Table{
+		Header: []string{"Issue", "Source", "Function", "Line", "Detail"},
+		Rows:   make([][]string, 0, len(issues)),
+	}
+	for _, is := range issues {
+		target, line := "", ""
+		if is.target != nil {
+			target = is.target.Path
+		}
+		if is.line > 0 {
+			line = strconv.Itoa(is.line)
+		}
+		table.Rows = append(table.Rows, []string{is.issue, is.source.Path, target, line, is.detail})
+	}
+	return table
+}
+
+// writeTree writes the calls of each program as a tree. The programs and
+// the functions that are not called are roots of the tree, then the
+// functions only called in a cycle. A function already in the branch is
+// marked as recursive.
+func (g *callGraph) writeTree(w io.Writer) {
+	shown := make(map[*dmp.Object]bool)
+	var branch func(obj *dmp.Object, depth int, path []*dmp.Object)
+	branch = func(obj *dmp.Object, depth int, path []*dmp.Object) {
+		shown[obj] = true
+		for _, callee := range g.callees(obj) {
+			n := 0
+			for _, c := range g.calls {
+				if c.caller == obj && c.callee == callee {
+					n++
+				}
+			}
+			label := fmt.Sprintf("%s%s (%d)", strings.Repeat("  ", depth), callee.Name, n)
+			if slices.Contains(path, callee) {
+				fmt.Fprintln(w, label+" recursive")
+				continue
+			}
+			fmt.Fprintln(w, label)
+			branch(callee, depth+1, append(path, callee))
+		}
+	}
+	for _, obj := range g.code {
+		if isFunction(obj) && g.isCalled(obj) {
+			continue
+		}
+		fmt.Fprintln(w, obj.Path)
+		branch(obj, 1, []*dmp.Object{obj})
+	}
+	for _, obj := range g.code {
+		if !shown[obj] {
+			fmt.Fprintln(w, obj.Path)
+			branch(obj, 1, []*dmp.Object{obj})
+		}
+	}
+}
+
+// writeDot writes the call graph in Graphviz dot format, the edges are
+// labelled with the number of calls.
+func (g *callGraph) writeDot(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph calls {\n  rankdir=LR;\n")
+	for _, obj := range g.code {
+		shape := "box"
+		if isFunction(obj) {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(b, "  %s [label=%s, shape=%s];\n", digraph.DotQuote(obj.Path), digraph.DotQuote(obj.Name), shape)
+	}
+	counts := make(map[[2]*dmp.Object]int)
+	order := make([][2]*dmp.Object, 0)
+	for _, c := range g.calls {
+		key := [2]*dmp.Object{c.caller, c.callee}
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	for _, key := range order {
+		fmt.Fprintf(b, "  %s -> %s [label=\"%d\"];\n", digraph.DotQuote(key[0].Path), digraph.DotQuote(key[1].Path), counts[key])
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,133 @@
+package calls
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const callsDump = `Path : NUSite
+BeginController : Ctrl1
+Object : Main
+Type : InfinityProgram
+ByteCode
+Numeric X
+X = Calc(1, 2)
+X = Calc(X) + ABS(X)
+X = Fact(X)
+EndByteCode
+EndObject
+Object : Calc
+Type : InfinityFunction
+ByteCode
+Arg 1 A
+Arg 2 B
+Return (A * B)
+EndByteCode
+EndObject
+Object : Fact
+Type : InfinityFunction
+ByteCode
+Arg 1 N
+If N < 2 Then Return (1)
+Return (N * Fact(N - 1))
+EndByteCode
+EndObject
+Object : Ping
+Type : InfinityFunction
+ByteCode
+Arg 1 N
+Return (Pong(N))
+EndByteCode
+EndObject
+Object : Pong
+Type : InfinityFunction
+ByteCode
+Arg 1 N
+Return (Ping(N))
+EndByteCode
+EndObject
+Object : Spare
+Type : InfinityFunction
+ByteCode
+Return (0)
+EndByteCode
+EndObject
+Object : Broken
+Type : InfinityProgram
+ByteCode
+X = Calc(1, 2) +
+X = Calc(1, 2)
+EndByteCode
+EndObject
+EndController
+`
+
+func testGraph() *callGraph {
+	x := dmp.NewIndex()
+	dmp.Parse(strings.NewReader(callsDump), x)
+	return buildGraph(x)
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := testGraph()
+
+	expected := []string{
+		"Main Calc 2:5 2",
+		"Main Calc 3:5 1",
+		"Main Fact 4:5 1",
+		"Fact Fact 3:13 1",
+		"Ping Pong 2:9 1",
+		"Pong Ping 2:9 1",
+		"Broken Calc 2:5 2",
+	}
+	got := make([]string, len(g.calls))
+	for i, c := range g.calls {
+		got[i] = fmt.Sprintf("%s %s %s %d", c.caller.Name, c.callee.Name, c.pos, c.args)
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestIssues(t *testing.T) {
+
+	expected := []string{
+		"syntax error Broken expected a value, found end of line",
+		"unused function Spare not called",
+		"recursion Fact calls itself",
+		"recursion Ping " + filepath.Join("NUSite", "Ctrl1", "Ping") + " -> " + filepath.Join("NUSite", "Ctrl1", "Pong"),
+		"wrong argument count Main 1 arguments, Calc declares 2",
+	}
+
+	issues := testGraph().issues()
+	got := make([]string, len(issues))
+	for i, is := range issues {
+		got[i] = fmt.Sprintf("%s %s %s", is.issue, is.source.Name, is.detail)
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestWriteDot(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := testGraph().writeDot(buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"digraph calls {",
+		`[label="Calc", shape=ellipse];`,
+		`[label="Main", shape=box];`,
+		`Calc" [label="2"];`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in\n%s", s, out)
+		}
+	}
+}
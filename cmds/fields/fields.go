@@ -0,0 +1,278 @@
+package fields
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const (
+	// enumLimit is the max number of distinct values for an enum
+	enumLimit = 12
+
+	// sampleWidth is the max length of a sample value
+	sampleWidth = 24
+)
+
+// property is the inventory of a property for an object type
+type property struct {
+	name   string
+	count  int
+	values map[string]int
+}
+
+// objectType is the inventory of the properties for an object type
+type objectType struct {
+	name       string
+	count      int
+	properties map[string]*property
+}
+
+type fieldsHandler struct {
+	dmp.EmptyHandler
+	types   []string
+	objects map[string]*objectType
+}
+
+func (h *fieldsHandler) Object(do *dmp.Object) {
+
+	if len(h.types) > 0 && !slices.Contains(h.types, do.Type) {
+		return
+	}
+
+	ot, ok := h.objects[do.Type]
+	if !ok {
+		ot = &objectType{
+			name:       do.Type,
+			properties: make(map[string]*property),
+		}
+		h.objects[do.Type] = ot
+	}
+	ot.count++
+
+	for k, v := range do.Properties {
+		p, ok := ot.properties[k]
+		if !ok {
+			p = &property{
+				name:   k,
+				values: make(map[string]int),
+			}
+			ot.properties[k] = p
+		}
+		p.count++
+		p.values[v]++
+	}
+}
+
+type Command struct {
+	FileName string
+	OutFile  string
+	Types    []string
+	Samples  int
+}
+
+func (cmd *Command) Execute() {
+
+	if cmd.Samples < 0 {
+		fmt.Println("the number of samples can not be negative")
+		return
+	}
+
+	h := &fieldsHandler{
+		types:   cmd.Types,
+		objects: make(map[string]*objectType),
+	}
+
+	dmp.ParseFile(cmd.FileName, h)
+
+	if len(h.objects) == 0 {
+		fmt.Println("no results")
+		return
+	}
+
+	w := os.Stdout
+	if cmd.OutFile != "" {
+		f, err := os.Create(cmd.OutFile)
+		if err != nil {
+			fmt.Println("could not create file")
+			return
+		}
+		defer func() {
+			f.Sync()
+			f.Close()
+		}()
+		w = f
+	}
+
+	names := make([]string, 0, len(h.objects))
+	for k := range h.objects {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		writeType(w, h.objects[name], cmd.Samples)
+	}
+}
+
+// writeType writes the property report for the object type
+func writeType(w io.Writer, ot *objectType, samples int) {
+
+	name := ot.name
+	if name == "" {
+		name = "(no type)"
+	}
+	fmt.Fprintf(w, "%s (%d objects)\n\n", name, ot.count)
+
+	props := make([]*property, 0, len(ot.properties))
+	for _, p := range ot.properties {
+		props = append(props, p)
+	}
+	slices.SortFunc(props, func(a, b *property) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	table := make([][]string, 0, len(props)+1)
+	table = append(table, []string{"Property", "Count", "Distinct", "Type", "Samples"})
+	for _, p := range props {
+		table = append(table, []string{
+			p.name,
+			strconv.Itoa(p.count),
+			strconv.Itoa(len(p.values)),
+			inferType(p.values),
+			strings.Join(sampleValues(p.values, samples), ", "),
+		})
+	}
+	printTable(w, table)
+	fmt.Fprintln(w)
+}
+
+// printTable prints the table indented with padded columns.
+// the count columns are right aligned.
+func printTable(w io.Writer, table [][]string) {
+
+	widths := make([]int, len(table[0]))
+	for _, row := range table {
+		for i, col := range row {
+			widths[i] = max(widths[i], len(col))
+		}
+	}
+
+	for r, row := range table {
+		if r == 1 {
+			fmt.Fprint(w, " ")
+			for _, n := range widths {
+				fmt.Fprintf(w, "  %s", strings.Repeat("-", n))
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, " ")
+		for i, col := range row {
+			switch i {
+			case 1, 2:
+				fmt.Fprintf(w, "  %*s", widths[i], col)
+			case len(row) - 1:
+				fmt.Fprintf(w, "  %s", col)
+			default:
+				fmt.Fprintf(w, "  %-*s", widths[i], col)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// sampleValues returns up to n of the most common non empty values
+// shortened to a single line.
+func sampleValues(values map[string]int, n int) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if strings.TrimSpace(k) != "" {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if values[a] != values[b] {
+			return values[b] - values[a]
+		}
+		return strings.Compare(a, b)
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	for i, k := range keys {
+		keys[i] = shorten(k)
+	}
+	return keys
+}
+
+// shorten returns the first line of the value limited to sampleWidth
+func shorten(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i]) + "..."
+	}
+	if r := []rune(s); len(r) > sampleWidth {
+		s = string(r[:sampleWidth-3]) + "..."
+	}
+	return s
+}
+
+// inferType returns the data type that fits all the non empty values.
+//
+// the types are int, float, timestamp, path, text (multi line), enum
+// (few distinct values that repeat) and string.
+func inferType(values map[string]int) string {
+
+	isInt, isFloat, isTime, isPath, isText := true, true, true, true, false
+	total, distinct := 0, 0
+
+	for v, n := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		total += n
+		distinct++
+
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			isInt = false
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			isFloat = false
+		}
+		if _, err := dmp.ParseTime(v); err != nil {
+			isTime = false
+		}
+		if !strings.Contains(v, `\`) || strings.ContainsAny(v, " \t\n") {
+			isPath = false
+		}
+		if strings.Contains(v, "\n") {
+			isText = true
+		}
+	}
+
+	switch {
+	case distinct == 0:
+		return "empty"
+	case isText:
+		return "text"
+	case isInt:
+		return "int"
+	case isFloat:
+		return "float"
+	case isTime:
+		return "timestamp"
+	case isPath:
+		return "path"
+	case distinct <= enumLimit && total > distinct:
+		return "enum"
+	default:
+		return "string"
+	}
+}
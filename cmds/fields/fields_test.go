@@ -0,0 +1,73 @@
+package fields
+
+import (
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]int
+		expected string
+	}{
+		{"int", map[string]int{"1": 3, "20": 1, "-4": 1}, "int"},
+		{"float", map[string]int{"1.5": 1, "20": 1}, "float"},
+		{"timestamp", map[string]int{"1/2/2024 3:04:05 PM": 1, "12/30/2023 11:00:00 AM": 1}, "timestamp"},
+		{"path", map[string]int{`Site\Ctrl\Point`: 1, `Site\Ctrl\Other`: 1}, "path"},
+		{"enum", map[string]int{"Voltage": 6, "Current": 3, "Digital": 2}, "enum"},
+		{"string", map[string]int{"Supply Air": 1, "Return Air": 1}, "string"},
+		{"text", map[string]int{"Line 1\nGoto 1": 1}, "text"},
+		{"empty", map[string]int{"": 4}, "empty"},
+		{"ignore empty", map[string]int{"": 4, "7": 1}, "int"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := inferType(test.values); result != test.expected {
+				t.Errorf("expected %q got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestSampleValues(t *testing.T) {
+	values := map[string]int{
+		"Voltage":                       2,
+		"Current":                       5,
+		"":                              9,
+		"Digital":                       2,
+		"Line 1\nGoto 1":                1,
+		"a very long value that is cut": 3,
+	}
+	expected := []string{"Current", "a very long value tha...", "Digital"}
+	result := sampleValues(values, 3)
+	if !slices.Equal(result, expected) {
+		t.Errorf("expected %q got %q", expected, result)
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"short", "short"},
+		{"  first line\nsecond line", "first line..."},
+		{"a very long value that is cut", "a very long value tha..."},
+		{"température de l'air soufflé élevée", "température de l'air ..."},
+		{"aaaaaaaaaaaaaaaaaaaaé long value", "aaaaaaaaaaaaaaaaaaaaé..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := shorten(tt.value)
+			if got != tt.expected {
+				t.Errorf("expected %q got %q", tt.expected, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("expected valid utf-8 got %q", got)
+			}
+		})
+	}
+}
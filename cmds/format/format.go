@@ -0,0 +1,122 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/pe"
+)
+
+// peExt is the extension of the program files written by the pe command
+const peExt = ".pe"
+
+type Command struct {
+	Paths []string
+	Check bool
+
+	// Unformatted is the count of the programs not formatted, set by Execute
+	Unformatted int
+}
+
+// Execute formats the program files and the dump files of the paths.
+// Directories are searched for program files. The programs that are not
+// formatted are listed, and with Check the files are not changed.
+func (cmd *Command) Execute() {
+	for _, path := range cmd.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		switch {
+		case info.IsDir():
+			err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && strings.EqualFold(filepath.Ext(name), peExt) {
+					cmd.formatFile(name)
+				}
+				return nil
+			})
+		case strings.EqualFold(filepath.Ext(path), peExt):
+			cmd.formatFile(path)
+		default:
+			err = cmd.formatDump(path)
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// formatFile formats a program file written by the pe command
+func (cmd *Command) formatFile(name string) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	code := pe.Format(string(b))
+	if code == string(b) {
+		return
+	}
+	cmd.Unformatted++
+	fmt.Println(name)
+	if cmd.Check {
+		return
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := os.WriteFile(name, []byte(code), info.Mode()); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// formatDump formats the ByteCode of the programs in the dump file, the
+// dump is written back only if a program is changed.
+func (cmd *Command) formatDump(name string) error {
+	r, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	changed := 0
+	buf := &bytes.Buffer{}
+	err = dmp.Rewrite(r, buf, func(obj *dmp.Object) map[string]string {
+		code, ok := obj.Properties["ByteCode"]
+		if !ok || !dmp.IsCode(obj.Type) {
+			return nil
+		}
+		formatted := pe.Format(code)
+		if formatted == code {
+			return nil
+		}
+		changed++
+		fmt.Printf("%s: %s\n", name, obj.Path)
+		return map[string]string{"ByteCode": formatted}
+	})
+	if err != nil {
+		return err
+	}
+	r.Close()
+
+	cmd.Unformatted += changed
+	if changed == 0 || cmd.Check {
+		return nil
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), info.Mode())
+}
@@ -0,0 +1,50 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const formatDump = `Path : NUSite
+BeginController : Ctrl1
+Object : Good
+Type : InfinityProgram
+ByteCode
+x = 1
+EndByteCode
+EndObject
+Object : Bad
+Type : InfinityProgram
+ByteCode
+if x then
+y=2
+endif
+EndByteCode
+EndObject
+EndController
+`
+
+func TestFormatDump(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "site.dmp")
+	if err := os.WriteFile(name, []byte(formatDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &Command{Paths: []string{name}, Check: true}
+	check.Execute()
+	if check.Unformatted != 1 {
+		t.Errorf("expected 1 unformatted program got %d", check.Unformatted)
+	}
+	if b, _ := os.ReadFile(name); string(b) != formatDump {
+		t.Error("expected the dump to be unchanged by the check")
+	}
+
+	cmd := &Command{Paths: []string{name}}
+	cmd.Execute()
+	cmd = &Command{Paths: []string{name}, Check: true}
+	cmd.Execute()
+	if cmd.Unformatted != 0 {
+		t.Errorf("expected the dump to be formatted, got %d unformatted", cmd.Unformatted)
+	}
+}
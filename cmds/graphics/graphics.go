@@ -0,0 +1,172 @@
+package graphics
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// graphicsType is the object type of the graphics panels
+const graphicsType = "Graphics"
+
+// kinds of panel bindings
+const (
+	kindPoint = "point"
+	kindLink  = "link"
+)
+
+// statuses of the bound paths
+const (
+	statusResolved = "resolved"
+	statusMissing  = "missing"
+	statusExternal = "external"
+)
+
+// binding is a path a panel object is bound to
+type binding struct {
+	graphic *dmp.Object
+	object  *panelObject
+	path    string
+	kind    string
+	status  string
+}
+
+type Command struct {
+	FileName string
+	OutFile  string
+	Format   string
+	SheetBy  string
+	Missing  bool
+	Links    bool
+}
+
+func (cmd *Command) Execute() {
+
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	x := dmp.NewIndex()
+	dmpPath := dmp.ParseFile(cmd.FileName, x)
+
+	bindings := findBindings(x, dmpPath)
+
+	title := "Graphics panel objects"
+	table := objectTable
+	switch {
+	case cmd.Missing:
+		title = "Graphics bound to missing points"
+		bindings = slices.DeleteFunc(bindings, func(b *binding) bool {
+			return b.status != statusMissing
+		})
+	case cmd.Links:
+		title = "Graphics panel links"
+		table = linkTable
+		bindings = slices.DeleteFunc(bindings, func(b *binding) bool {
+			return b.kind != kindLink
+		})
+	}
+
+	if len(bindings) == 0 {
+		fmt.Println("No panel objects found.")
+		return
+	}
+
+	t := table(bindings)
+	t.SheetBy = cmd.SheetBy
+
+	err = output.Create(cmd.OutFile, func(w io.Writer) error {
+		if format == output.Text {
+			fmt.Fprintf(w, "%s\n\n  Source device: %s\n\n", title, dmpPath)
+		}
+		return output.Write(w, format, t)
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// findBindings returns the panel objects of all the graphics with the
+// paths they are bound to. Paths to other graphics are links, all other
+// paths are points. Panel objects without a path have a single binding
+// with an empty path.
+func findBindings(x *dmp.Index, dmpPath string) []*binding {
+	bindings := make([]*binding, 0)
+	for _, g := range x.Objects() {
+		if g.Type != graphicsType {
+			continue
+		}
+		for _, po := range parsePanelObjects(g.Properties["PanelObjectList"]) {
+			if len(po.Paths) == 0 {
+				bindings = append(bindings, &binding{graphic: g, object: po})
+				continue
+			}
+			for _, p := range po.Paths {
+				b := &binding{
+					graphic: g,
+					object:  po,
+					path:    p,
+					kind:    kindPoint,
+					status:  statusExternal,
+				}
+				if obj := x.Resolve(p, g.Path); obj != nil {
+					b.status = statusResolved
+					if obj.Type == graphicsType {
+						b.kind = kindLink
+					}
+				} else if dmp.HasPathPrefix(p, dmpPath) {
+					b.status = statusMissing
+				}
+				bindings = append(bindings, b)
+			}
+		}
+	}
+	return bindings
+}
+
+func objectTable(bindings []*binding) *output.Table {
+	table := &output.Table{
+		Header: []string{"Graphic", "Line", "Object Type", "Path", "Kind", "Status", "X", "Y", "Format"},
+		Rows:   make([][]string, 0, len(bindings)),
+	}
+	for _, b := range bindings {
+		x, y := "", ""
+		if b.object.HasPosition {
+			x, y = strconv.Itoa(b.object.X), strconv.Itoa(b.object.Y)
+		}
+		table.Rows = append(table.Rows, []string{
+			b.graphic.Path,
+			strconv.Itoa(b.object.Line),
+			b.object.Type,
+			b.path,
+			b.kind,
+			b.status,
+			x,
+			y,
+			b.object.Format,
+		})
+	}
+	return table
+}
+
+func linkTable(bindings []*binding) *output.Table {
+	table := &output.Table{
+		Header: []string{"Graphic", "Linked Panel", "Line", "Object Type"},
+		Rows:   make([][]string, 0, len(bindings)),
+	}
+	for _, b := range bindings {
+		table.Rows = append(table.Rows, []string{
+			b.graphic.Path,
+			b.path,
+			strconv.Itoa(b.object.Line),
+			b.object.Type,
+		})
+	}
+	return table
+}
@@ -0,0 +1,58 @@
+package graphics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const graphicsDump = `Path : NUSite
+BeginController : Ctrl1
+Object : AHU1
+Type : Graphics
+PanelObjectList
+{
+  Value : NUSite\Ctrl1\SAT, X = 10, Y = 20, Format = "##.#"
+  Value : NUSite\Ctrl1\Gone, X = 10, Y = 40
+  Button : NUSite\Ctrl1\AHU2, X = 0, Y = 0
+  Value : NUSite\Ctrl2\OAT, X = 10, Y = 60
+  Text (5, 5) "Supply Air"
+}
+EndObject
+Object : AHU2
+Type : Graphics
+EndObject
+Object : SAT
+Type : InfinityInput
+EndObject
+EndController
+`
+
+func TestFindBindings(t *testing.T) {
+	x := dmp.NewIndex()
+	dmp.Parse(strings.NewReader(graphicsDump), x)
+
+	bindings := findBindings(x, `NUSite\Ctrl1`)
+
+	expected := []struct {
+		path   string
+		kind   string
+		status string
+	}{
+		{`NUSite\Ctrl1\SAT`, kindPoint, statusResolved},
+		{`NUSite\Ctrl1\Gone`, kindPoint, statusMissing},
+		{`NUSite\Ctrl1\AHU2`, kindLink, statusResolved},
+		{`NUSite\Ctrl2\OAT`, kindPoint, statusExternal},
+		{"", "", ""},
+	}
+	if len(bindings) != len(expected) {
+		t.Fatalf("expected %d bindings got %d", len(expected), len(bindings))
+	}
+	for i, e := range expected {
+		b := bindings[i]
+		if b.path != e.path || b.kind != e.kind || b.status != e.status {
+			t.Errorf("expected %v got %s %s %s", e, b.path, b.kind, b.status)
+		}
+	}
+}
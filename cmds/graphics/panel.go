@@ -0,0 +1,96 @@
+package graphics
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// panelObject is an object of a graphics panel found in the
+// PanelObjectList of a Graphics object.
+type panelObject struct {
+	// Line is the line number in the PanelObjectList
+	Line int
+
+	// Type is the kind of panel object, like Value or Button
+	Type string
+
+	// Paths is the paths of the points and panels the object is bound to
+	Paths []string
+
+	// X and Y is the position of the object, when found
+	X, Y        int
+	HasPosition bool
+
+	// Format is the display format of the value
+	Format string
+
+	// Text is the line of the object
+	Text string
+}
+
+var (
+	panelPath   = regexp.MustCompile(`[A-Za-z0-9_.]*\\[A-Za-z0-9_.\\]*[A-Za-z0-9_]`)
+	panelType   = regexp.MustCompile(`(?i)\b(?:obj)?type\s*[=:]\s*"?([A-Za-z][A-Za-z0-9_]*)`)
+	panelWord   = regexp.MustCompile(`^[{\s]*([A-Za-z][A-Za-z0-9_]*)`)
+	panelX      = regexp.MustCompile(`(?i)\b(?:x|left)\s*[=:]\s*(-?\d+)`)
+	panelY      = regexp.MustCompile(`(?i)\b(?:y|top)\s*[=:]\s*(-?\d+)`)
+	panelPair   = regexp.MustCompile(`\(\s*(-?\d+)\s*,\s*(-?\d+)\s*\)`)
+	panelFormat = regexp.MustCompile(`(?i)\bformat\s*[=:]\s*("[^"]*"|[^,\s}]+)`)
+	panelPic    = regexp.MustCompile(`"([^"]*[#0][^"]*)"`)
+)
+
+// parsePanelObjects returns the panel objects of the PanelObjectList.
+//
+// The layout of the list is not documented and has not been checked
+// against the lists written by Continuum, so it is kept with the graphics
+// report until it is. The list is read one
+// object per line and the values are found by their names where they
+// have one: Type or ObjType, X or Left, Y or Top, and Format. Otherwise
+// the type is the first word of the line, the position is a pair of
+// numbers in parentheses and the format is a quoted picture like "##.#".
+// The paths are all the backslash paths on the line. Lines without a
+// path, position or format are skipped.
+func parsePanelObjects(s string) []*panelObject {
+	objects := make([]*panelObject, 0)
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "{" || line == "}" {
+			continue
+		}
+		po := &panelObject{
+			Line:  i + 1,
+			Text:  line,
+			Paths: panelPath.FindAllString(line, -1),
+		}
+
+		if m := panelType.FindStringSubmatch(line); m != nil {
+			po.Type = m[1]
+		} else if m := panelWord.FindStringSubmatch(line); m != nil && !strings.Contains(m[1], `\`) {
+			po.Type = m[1]
+		}
+
+		mx, my := panelX.FindStringSubmatch(line), panelY.FindStringSubmatch(line)
+		if mx != nil && my != nil {
+			po.X, _ = strconv.Atoi(mx[1])
+			po.Y, _ = strconv.Atoi(my[1])
+			po.HasPosition = true
+		} else if m := panelPair.FindStringSubmatch(line); m != nil {
+			po.X, _ = strconv.Atoi(m[1])
+			po.Y, _ = strconv.Atoi(m[2])
+			po.HasPosition = true
+		}
+
+		if m := panelFormat.FindStringSubmatch(line); m != nil {
+			po.Format = strings.Trim(m[1], `"`)
+		} else if m := panelPic.FindStringSubmatch(line); m != nil {
+			po.Format = m[1]
+		}
+
+		if len(po.Paths) == 0 && !po.HasPosition && po.Format == "" {
+			continue
+		}
+		objects = append(objects, po)
+	}
+	return objects
+}
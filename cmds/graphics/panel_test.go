@@ -0,0 +1,35 @@
+package graphics
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePanelObjects(t *testing.T) {
+	s := `{
+  Value : NUSite\Ctrl1\SAT, X = 10, Y = 20, Format = "##.#"
+  ObjType : Button, Link : NUSite\Graphics\AHU2, Left : 5, Top : 6
+  Text (100, 200) "Supply Air"
+  Picture (1, 2) NUSite\Ctrl1\Fan.Value "###"
+  Background
+}`
+	objects := parsePanelObjects(s)
+
+	expected := []panelObject{
+		{Line: 2, Type: "Value", Paths: []string{`NUSite\Ctrl1\SAT`}, X: 10, Y: 20, HasPosition: true, Format: "##.#"},
+		{Line: 3, Type: "Button", Paths: []string{`NUSite\Graphics\AHU2`}, X: 5, Y: 6, HasPosition: true},
+		{Line: 4, Type: "Text", X: 100, Y: 200, HasPosition: true},
+		{Line: 5, Type: "Picture", Paths: []string{`NUSite\Ctrl1\Fan.Value`}, X: 1, Y: 2, HasPosition: true, Format: "###"},
+	}
+
+	if len(objects) != len(expected) {
+		t.Fatalf("expected %d objects got %d", len(expected), len(objects))
+	}
+	for i, e := range expected {
+		po := objects[i]
+		if po.Line != e.Line || po.Type != e.Type || !slices.Equal(po.Paths, e.Paths) ||
+			po.X != e.X || po.Y != e.Y || po.HasPosition != e.HasPosition || po.Format != e.Format {
+			t.Errorf("expected %+v got %+v", e, *po)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package grep
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+	"github.com/tpacheco/dmptool/pe"
+)
+
+const (
+	propByteCode = "ByteCode"
+	propPanel    = "PanelObjectList"
+)
+
+type Command struct {
+	FileName string
+	Pattern  string
+	OutFile  string
+	// Context is the number of lines written before and after a match
+	Context    int
+	Word       bool
+	IgnoreCase bool
+	// NoComments skips the comments of the code
+	NoComments bool
+	// Panels also searches the PanelObjectList of the graphics panels
+	Panels bool
+	// Properties are the other properties searched
+	Properties []string
+
+	// Matches is the count of the lines matched, set by Execute
+	Matches int
+	// Err is the error that stopped the search, set by Execute
+	Err error
+}
+
+// compile returns the regular expression of the pattern with the word and
+// case options
+func (cmd *Command) compile() (*regexp.Regexp, error) {
+	pattern := cmd.Pattern
+	if cmd.Word {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if cmd.IgnoreCase {
+		pattern = `(?i)` + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// properties returns the names of the properties searched, each once
+func (cmd *Command) properties() []string {
+	props := []string{propByteCode}
+	if cmd.Panels {
+		props = append(props, propPanel)
+	}
+	for _, p := range cmd.Properties {
+		if !slices.Contains(props, p) {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// Execute writes the lines of the properties matching the pattern as
+// path:line: text. The lines of the ByteCode are named by the path of the
+// object, the lines of the other properties by the path and the property.
+func (cmd *Command) Execute() {
+
+	re, err := cmd.compile()
+	if err != nil {
+		cmd.Err = err
+		fmt.Println(err)
+		return
+	}
+
+	r, err := os.Open(cmd.FileName)
+	if err != nil {
+		cmd.Err = err
+		fmt.Println(err)
+		return
+	}
+	x := dmp.NewIndex()
+	dmp.Parse(r, x)
+	r.Close()
+
+	err = output.Create(cmd.OutFile, func(w io.Writer) error {
+		s := &searcher{w: w, re: re, context: max(cmd.Context, 0)}
+		for _, obj := range x.Objects() {
+			for _, prop := range cmd.properties() {
+				value, ok := obj.Properties[prop]
+				if !ok {
+					continue
+				}
+				name := obj.Path
+				if prop != propByteCode {
+					name += "." + prop
+				}
+				text := lines(value)
+				search := text
+				if prop == propByteCode && cmd.NoComments {
+					search = removeComments(text)
+				}
+				s.search(name, text, search)
+			}
+		}
+		cmd.Matches = s.matches
+		return nil
+	})
+	if err != nil {
+		cmd.Err = err
+		fmt.Println(err)
+	}
+}
+
+// lines splits the text of a property into lines
+func lines(text string) []string {
+	list := strings.Split(text, "\n")
+	for i, s := range list {
+		list[i] = strings.TrimSuffix(s, "\r")
+	}
+	return list
+}
+
+// removeComments returns the lines of code without the comments
+func removeComments(code []string) []string {
+	list := make([]string, len(code))
+	copy(list, code)
+	for _, tk := range pe.Scan(strings.Join(code, "\n")) {
+		if tk.Kind == pe.TokComment && tk.Line <= len(list) {
+			i := tk.Line - 1
+			list[i] = list[i][:min(tk.Col-1, len(list[i]))]
+		}
+	}
+	return list
+}
+
+// searcher writes the matching lines with the context lines around them.
+// As with grep the matches are written path:line: text, the context lines
+// path-line- text, and the groups of lines that are not next to each other
+// are separated by --.
+type searcher struct {
+	w       io.Writer
+	re      *regexp.Regexp
+	context int
+	matches int
+	// written is true after the first group of lines is written
+	written bool
+}
+
+// search writes the lines where the search text matches. The search text
+// is the lines with the parts that are not searched removed.
+func (s *searcher) search(name string, text []string, search []string) {
+	// last is the index of the last line written, -1 before any
+	last := -1
+	for i, line := range search {
+		if !s.re.MatchString(line) {
+			continue
+		}
+		s.matches++
+		from := max(i-s.context, last+1)
+		if s.written && (last < 0 || from > last+1) && s.context > 0 {
+			fmt.Fprintln(s.w, "--")
+		}
+		for j := from; j < i; j++ {
+			fmt.Fprintf(s.w, "%s-%d- %s\n", name, j+1, text[j])
+		}
+		fmt.Fprintf(s.w, "%s:%d: %s\n", name, i+1, text[i])
+		last, s.written = i, true
+
+		// the lines after are written until the next match
+		for j := i + 1; j <= min(i+s.context, len(text)-1); j++ {
+			if s.re.MatchString(search[j]) {
+				break
+			}
+			fmt.Fprintf(s.w, "%s-%d- %s\n", name, j+1, text[j])
+			last = j
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package grep
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const grepCode = `Numeric Tmp
+Line Start
+  Tmp = SAT ' supply air temp
+  If Tmp > 72 Then Fan = On
+  Stop
+Line Other
+  ' Fan is off at night
+  Fan = Off
+  Tmp = Fanout`
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      Command
+		matches  int
+		expected string
+	}{
+		{
+			name:    "pattern",
+			cmd:     Command{Pattern: "Fan"},
+			matches: 4,
+			expected: `Loop:4:   If Tmp > 72 Then Fan = On
+Loop:7:   ' Fan is off at night
+Loop:8:   Fan = Off
+Loop:9:   Tmp = Fanout
+`,
+		},
+		{
+			name:    "word without comments",
+			cmd:     Command{Pattern: "fan", Word: true, IgnoreCase: true, NoComments: true},
+			matches: 2,
+			expected: `Loop:4:   If Tmp > 72 Then Fan = On
+Loop:8:   Fan = Off
+`,
+		},
+		{
+			name:    "comment only",
+			cmd:     Command{Pattern: "supply", NoComments: true},
+			matches: 0,
+		},
+		{
+			name:    "context",
+			cmd:     Command{Pattern: `^Line`, Context: 1},
+			matches: 2,
+			expected: `Loop-1- Numeric Tmp
+Loop:2: Line Start
+Loop-3-   Tmp = SAT ' supply air temp
+--
+Loop-5-   Stop
+Loop:6: Line Other
+Loop-7-   ' Fan is off at night
+`,
+		},
+		{
+			name:    "context overlap",
+			cmd:     Command{Pattern: `Fan = O`, Context: 2},
+			matches: 2,
+			expected: `Loop-2- Line Start
+Loop-3-   Tmp = SAT ' supply air temp
+Loop:4:   If Tmp > 72 Then Fan = On
+Loop-5-   Stop
+Loop-6- Line Other
+Loop-7-   ' Fan is off at night
+Loop:8:   Fan = Off
+Loop-9-   Tmp = Fanout
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			re, err := test.cmd.compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			s := &searcher{w: buf, re: re, context: test.cmd.Context}
+			text := lines(grepCode)
+			search := text
+			if test.cmd.NoComments {
+				search = removeComments(text)
+			}
+			s.search("Loop", text, search)
+			if buf.String() != test.expected {
+				t.Errorf("expected\n%s\ngot\n%s", test.expected, buf.String())
+			}
+			if s.matches != test.matches {
+				t.Errorf("expected %d matches got %d", test.matches, s.matches)
+			}
+		})
+	}
+}
+
+func TestProperties(t *testing.T) {
+	cmd := &Command{Panels: true, Properties: []string{"ByteCode", "Name", "PanelObjectList", "Name"}}
+	expected := []string{"ByteCode", "PanelObjectList", "Name"}
+	if got := cmd.properties(); !slices.Equal(got, expected) {
+		t.Errorf("expected %v got %v", expected, got)
+	}
+}
+
+func TestExecuteError(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  *Command
+	}{
+		{"invalid pattern", &Command{FileName: "site.dmp", Pattern: "("}},
+		{"missing file", &Command{FileName: filepath.Join(t.TempDir(), "missing.dmp"), Pattern: "Fan"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.cmd.Execute()
+			if test.cmd.Err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
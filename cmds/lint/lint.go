@@ -0,0 +1,113 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// FormatSARIF is the format name of the SARIF output
+const FormatSARIF = "sarif"
+
+var ErrUnknownSeverity = errors.New("unknown severity")
+
+type Command struct {
+	FileName string
+	OutFile  string
+	Format   string
+	SheetBy  string
+	Severity string
+}
+
+func (cmd *Command) Execute() {
+
+	sarif := strings.EqualFold(cmd.Format, FormatSARIF) ||
+		(cmd.Format == "" && strings.EqualFold(filepath.Ext(cmd.OutFile), ".sarif"))
+
+	format := output.Text
+	if !sarif {
+		var err error
+		format, err = output.Lookup(cmd.Format, cmd.OutFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	minimum := slices.Index(severities, strings.ToLower(cmd.Severity))
+	if cmd.Severity == "" {
+		minimum = 0
+	}
+	if minimum < 0 {
+		fmt.Printf("%s: %s, use %s\n", ErrUnknownSeverity, cmd.Severity, strings.Join(severities, ", "))
+		return
+	}
+
+	x := dmp.NewIndex()
+	l := &linter{x: x}
+	l.dmpPath = dmp.ParseFile(cmd.FileName, x)
+
+	programs := 0
+	for _, obj := range x.Objects() {
+		if dmp.IsCode(obj.Type) {
+			programs++
+			l.program(obj)
+		}
+	}
+	findings := slices.DeleteFunc(l.findings, func(f *finding) bool {
+		return slices.Index(severities, f.rule.severity) < minimum
+	})
+
+	err := output.Create(cmd.OutFile, func(w io.Writer) error {
+		if sarif {
+			return writeSARIF(w, findings)
+		}
+		if format == output.Text {
+			writeTitle(w, l.dmpPath, programs, findings)
+			if len(findings) == 0 {
+				return nil
+			}
+		}
+		table := findingTable(findings)
+		table.SheetBy = cmd.SheetBy
+		return output.Write(w, format, table)
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func findingTable(findings []*finding) *output.Table {
+	table := &output.Table{
+		Header: []string{"Program", "Line", "Col", "Severity", "Rule", "Message"},
+		Rows:   make([][]string, 0, len(findings)),
+	}
+	for _, f := range findings {
+		table.Rows = append(table.Rows, []string{
+			f.obj.Path,
+			strconv.Itoa(f.pos.Line),
+			strconv.Itoa(f.pos.Col),
+			f.rule.severity,
+			f.rule.id,
+			f.msg,
+		})
+	}
+	return table
+}
+
+// writeTitle writes the title and the count of findings for the text output
+func writeTitle(w io.Writer, dmpPath string, programs int, findings []*finding) {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.rule.severity]++
+	}
+	fmt.Fprintf(w, "Lint\n\n  Source device: %s\n  Programs: %d\n  Errors: %d\n  Warnings: %d\n  Notes: %d\n\n",
+		dmpPath, programs, counts[sevError], counts[sevWarning], counts[sevNote])
+}
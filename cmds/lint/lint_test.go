@@ -0,0 +1,163 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const lintDump = `Path : NUSite
+BeginController : Ctrl1
+Object : SAT
+Type : InfinityInput
+EndObject
+Object : Fan
+Type : InfinityOutput
+EndObject
+Object : Loop
+Type : InfinityProgram
+ByteCode
+Numeric Tmp, Unused
+Line Start
+  Tmp = SAT
+  SAT = 1
+  Turn On Fan
+  If Tmp = 72.5 Then Goto Finish
+  If Fan > Off Then Goto Start
+  If Tmp = Tmp Then Goto Start
+  NUSite\Ctrl1\Gone = 1
+  NUSite\Ctrl2\Remote = 1
+  Goto Start
+Line Never
+  Stop
+Line Finish
+  x = (1
+EndByteCode
+EndObject
+Object : Flow
+Type : InfinityProgram
+FlowType : FallThru
+ByteCode
+Line One
+  Fan = On
+  Goto Three
+Line Two
+  Fan = Off
+Line Three
+  Goto Nowhere
+Line Four
+  Fan = Off
+EndByteCode
+EndObject
+Object : Calc
+Type : InfinityFunction
+ByteCode
+Arg 1 X
+Arg 2 Y
+Return (X * 2)
+EndByteCode
+EndObject
+EndController
+`
+
+func lintTest() []*finding {
+	x := dmp.NewIndex()
+	dmp.Parse(strings.NewReader(lintDump), x)
+	l := &linter{x: x, dmpPath: `NUSite\Ctrl1`}
+	for _, obj := range x.Objects() {
+		if dmp.IsCode(obj.Type) {
+			l.program(obj)
+		}
+	}
+	return l.findings
+}
+
+func TestLint(t *testing.T) {
+
+	expected := []string{
+		"Loop 15:9 error syntax",
+		"Loop 12:6 warning unreachable-label",
+		"Loop 1:14 warning unused-local",
+		"Loop 4:3 error write-input",
+		"Loop 9:3 error missing-reference",
+		"Loop 6:10 warning suspicious-comparison",
+		"Loop 7:10 warning suspicious-comparison",
+		"Loop 8:10 warning suspicious-comparison",
+		"Flow 7:8 error undefined-label",
+		"Flow 4:6 warning unreachable-label",
+		"Flow 8:6 warning unreachable-label",
+		"Flow 9:3 warning fallthrough",
+		"Calc 2:7 note unused-argument",
+	}
+
+	findings := lintTest()
+	got := make([]string, len(findings))
+	for i, f := range findings {
+		got[i] = fmt.Sprintf("%s %s %s %s", f.obj.Name, f.pos, f.rule.severity, f.rule.id)
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := writeSARIF(buf, lintTest()); err != nil {
+		t.Fatal(err)
+	}
+	log := sarifLog{}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("expected a single run of version %s", sarifVersion)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(rules) {
+		t.Errorf("expected %d rules got %d", len(rules), len(run.Tool.Driver.Rules))
+	}
+	r := run.Results[0]
+	loc := r.Locations[0].PhysicalLocation
+	if r.RuleId != "syntax" || r.Level != "error" {
+		t.Errorf("expected a syntax error got %s %s", r.RuleId, r.Level)
+	}
+	if loc.ArtifactLocation.URI != "NUSite/Ctrl1/Loop.pe" {
+		t.Errorf("expected NUSite/Ctrl1/Loop.pe got %s", loc.ArtifactLocation.URI)
+	}
+	if loc.Region.StartLine != 15 || loc.Region.StartColumn != 9 {
+		t.Errorf("expected 15:9 got %d:%d", loc.Region.StartLine, loc.Region.StartColumn)
+	}
+}
+
+func TestIsFallthrough(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      *dmp.Object
+		expected bool
+	}{
+		{"function", &dmp.Object{Type: "InfinityFunction"}, true},
+		{"looping", &dmp.Object{Type: "InfinityProgram", Properties: map[string]string{"FlowType": "Looping"}}, false},
+		{"fall through", &dmp.Object{Type: "InfinityProgram", Properties: map[string]string{"FlowType": "FallThru"}}, true},
+		{"no flow type", &dmp.Object{Type: "InfinityProgram", Properties: map[string]string{}}, false},
+		{
+			name: "other flow properties",
+			obj: &dmp.Object{Type: "InfinityProgram", Properties: map[string]string{
+				"AirFlowMode": "Fallback",
+				"FlowType":    "Looping",
+				"Overflow":    "FallThru",
+			}},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFallthrough(tt.obj); got != tt.expected {
+				t.Errorf("expected %v got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/pe"
+)
+
+// severities of the findings, from the least to the most severe
+const (
+	sevNote    = "note"
+	sevWarning = "warning"
+	sevError   = "error"
+)
+
+var severities = []string{sevNote, sevWarning, sevError}
+
+// rule is a check made on the code of the programs
+type rule struct {
+	id          string
+	severity    string
+	description string
+}
+
+var (
+	ruleSyntax           = &rule{"syntax", sevError, "The code could not be parsed."}
+	ruleUndefinedLabel   = &rule{"undefined-label", sevError, "Goto to a line label that is not defined."}
+	ruleUnreachableLabel = &rule{"unreachable-label", sevWarning, "Line label that is never reached by a Goto or by falling through."}
+	ruleUnusedLocal      = &rule{"unused-local", sevWarning, "Local variable declared but not used."}
+	ruleUnusedArgument   = &rule{"unused-argument", sevNote, "Function argument declared but not used."}
+	ruleWriteInput       = &rule{"write-input", sevError, "Program writes to an input point."}
+	ruleMissingReference = &rule{"missing-reference", sevError, "Reference to an object missing from the dump device."}
+	ruleFallthrough      = &rule{"fallthrough", sevWarning, "Fall through program without a Goto, Stop or Return at the end of the code."}
+	ruleComparison       = &rule{"suspicious-comparison", sevWarning, "Comparison that is always true, always false or unreliable."}
+
+	rules = []*rule{
+		ruleSyntax,
+		ruleUndefinedLabel,
+		ruleUnreachableLabel,
+		ruleUnusedLocal,
+		ruleUnusedArgument,
+		ruleWriteInput,
+		ruleMissingReference,
+		ruleFallthrough,
+		ruleComparison,
+	}
+)
+
+// finding is a problem found in a program
+type finding struct {
+	obj  *dmp.Object
+	pos  pe.Pos
+	rule *rule
+	msg  string
+}
+
+// linter checks the programs of a dump
+type linter struct {
+	x        *dmp.Index
+	dmpPath  string
+	findings []*finding
+}
+
+func (l *linter) report(obj *dmp.Object, pos pe.Pos, r *rule, format string, args ...any) {
+	l.findings = append(l.findings, &finding{
+		obj:  obj,
+		pos:  pos,
+		rule: r,
+		msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+// propFlowType is the property of the programs that sets if they loop on
+// the current line or fall through, Looping or FallThru
+const propFlowType = "FlowType"
+
+// isFallthrough tests if the program runs from one line into the next.
+// Functions always fall through, programs use the flow type property
+// and loop by default.
+func isFallthrough(obj *dmp.Object) bool {
+	if obj.Type == "InfinityFunction" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(obj.Properties[propFlowType]), "fall")
+}
+
+// program checks the code of a single program
+func (l *linter) program(obj *dmp.Object) {
+	code, ok := obj.Properties["ByteCode"]
+	if !ok {
+		return
+	}
+	f, errs := pe.Parse(code)
+	for _, err := range errs {
+		l.report(obj, err.Pos, ruleSyntax, "%s", err.Msg)
+	}
+	l.labels(obj, f)
+	locals := l.locals(obj, f)
+	l.references(obj, f, locals)
+	l.comparisons(obj, f)
+	l.fallthroughGuard(obj, f)
+}
+
+func baseName(name string) string {
+	name = strings.ToLower(name)
+	if i := strings.LastIndexByte(name, '.'); i > strings.LastIndexByte(name, '\\') {
+		return name[:i]
+	}
+	return name
+}
+
+// isJump tests if the statement always leaves the line
+func isJump(s pe.Stmt) bool {
+	switch s := s.(type) {
+	case *pe.Goto, *pe.Return:
+		return true
+	case *pe.Command:
+		return strings.EqualFold(s.Keyword, "stop")
+	}
+	return false
+}
+
+// labels checks the Goto targets and that each line label can be reached
+func (l *linter) labels(obj *dmp.Object, f *pe.File) {
+	defined := make(map[string]bool)
+	targeted := make(map[string]bool)
+	gotos := make([]*pe.Goto, 0)
+	pe.Inspect(f, func(n pe.Node) bool {
+		switch n := n.(type) {
+		case *pe.Label:
+			defined[strings.ToLower(n.Name.Name)] = true
+		case *pe.Goto:
+			targeted[strings.ToLower(n.Label.Name)] = true
+			gotos = append(gotos, n)
+		}
+		return true
+	})
+
+	for _, g := range gotos {
+		if !defined[strings.ToLower(g.Label.Name)] {
+			l.report(obj, g.Label.Pos, ruleUndefinedLabel, "Goto to undefined line %s", g.Label.Name)
+		}
+	}
+
+	// the first line is reached when the program starts, the other lines
+	// are reached by a Goto, or from the line before in a fall through
+	// program unless it always jumps away.
+	flows := isFallthrough(obj)
+	started := false
+	falls := true
+	for _, s := range f.Stmts {
+		switch s := s.(type) {
+		case *pe.Label:
+			reached := targeted[strings.ToLower(s.Name.Name)] || !started || (flows && falls)
+			if !reached {
+				l.report(obj, s.Name.Pos, ruleUnreachableLabel, "line %s is never reached", s.Name.Name)
+			}
+			started = true
+		case *pe.Declare, *pe.ArgDecl:
+			// declarations are not run
+		default:
+			started = true
+			falls = !isJump(s)
+		}
+	}
+}
+
+// locals checks that the declared locals are used and returns their names
+func (l *linter) locals(obj *dmp.Object, f *pe.File) map[string]bool {
+	declared := make([]*pe.Ident, 0)
+	args := make(map[*pe.Ident]bool)
+	used := make(map[string]bool)
+	pe.Inspect(f, func(n pe.Node) bool {
+		switch n := n.(type) {
+		case *pe.Declare:
+			declared = append(declared, n.Names...)
+			return false
+		case *pe.ArgDecl:
+			declared = append(declared, n.Name)
+			args[n.Name] = true
+			return false
+		case *pe.Label, *pe.Goto:
+			return false
+		case *pe.Ident:
+			used[baseName(n.Name)] = true
+		}
+		return true
+	})
+
+	locals := make(map[string]bool)
+	for _, id := range declared {
+		name := strings.ToLower(id.Name)
+		locals[name] = true
+		switch {
+		case used[name]:
+		case args[id]:
+			l.report(obj, id.Pos, ruleUnusedArgument, "argument %s is not used", id.Name)
+		default:
+			l.report(obj, id.Pos, ruleUnusedLocal, "%s is declared but not used", id.Name)
+		}
+	}
+	return locals
+}
+
+// references checks the names used by the program against the objects of
+// the dump, paths to missing objects and writes to inputs are reported.
+func (l *linter) references(obj *dmp.Object, f *pe.File, locals map[string]bool) {
+	writes := make(map[*pe.Ident]bool)
+	pe.Inspect(f, func(n pe.Node) bool {
+		switch n := n.(type) {
+		case *pe.Declare, *pe.ArgDecl, *pe.Label, *pe.Goto:
+			return false
+		case *pe.Assign:
+			writes[n.Target] = true
+		case *pe.Turn:
+			for _, id := range n.Targets {
+				writes[id] = true
+			}
+		case *pe.Ident:
+			if locals[baseName(n.Name)] {
+				return true
+			}
+			target := l.x.Resolve(n.Name, obj.Path)
+			if target == nil {
+				// names without a path can be system variables
+				if strings.Contains(n.Name, `\`) && dmp.HasPathPrefix(n.Name, l.dmpPath) {
+					l.report(obj, n.Pos, ruleMissingReference, "%s is not in the dump", n.Name)
+				}
+				return true
+			}
+			if writes[n] && strings.HasSuffix(target.Type, "Input") {
+				l.report(obj, n.Pos, ruleWriteInput, "writes to %s %s", target.Type, n.Name)
+			}
+		}
+		return true
+	})
+}
+
+var comparisons = []string{"=", "<>", "<", ">", "<=", ">="}
+
+// exprText returns the text of simple expressions, or "" for the others
+func exprText(x pe.Expr) string {
+	switch x := x.(type) {
+	case *pe.Ident:
+		return strings.ToLower(x.Name)
+	case *pe.Number:
+		return x.Value
+	case *pe.Constant:
+		return strings.ToLower(x.Name)
+	case *pe.Paren:
+		return exprText(x.X)
+	}
+	return ""
+}
+
+func isDecimal(x pe.Expr) bool {
+	n, ok := x.(*pe.Number)
+	return ok && strings.ContainsAny(n.Value, ".eE")
+}
+
+func isConstant(x pe.Expr) bool {
+	_, ok := x.(*pe.Constant)
+	return ok
+}
+
+// comparisons checks for comparisons of a value with itself, equality
+// with decimal numbers and the order of On and Off.
+func (l *linter) comparisons(obj *dmp.Object, f *pe.File) {
+	pe.Inspect(f, func(n pe.Node) bool {
+		b, ok := n.(*pe.Binary)
+		if !ok || !slices.Contains(comparisons, b.Op) {
+			return true
+		}
+		equality := b.Op == "=" || b.Op == "<>"
+		switch {
+		case exprText(b.X) != "" && exprText(b.X) == exprText(b.Y):
+			l.report(obj, b.Pos, ruleComparison, "%s is compared with itself", exprText(b.X))
+		case equality && (isDecimal(b.X) || isDecimal(b.Y)):
+			l.report(obj, b.Pos, ruleComparison, "exact comparison with a decimal number, compare with a range")
+		case !equality && (isConstant(b.X) || isConstant(b.Y)):
+			l.report(obj, b.Pos, ruleComparison, "%s compares the order of On, Off, True or False", b.Op)
+		}
+		return true
+	})
+}
+
+// fallthroughGuard checks that a fall through program does not run off
+// the end of the code.
+func (l *linter) fallthroughGuard(obj *dmp.Object, f *pe.File) {
+	if obj.Type == "InfinityFunction" || !isFallthrough(obj) {
+		return
+	}
+	pos := pe.Pos{Line: 1, Col: 1}
+	for i := len(f.Stmts) - 1; i >= 0; i-- {
+		s := f.Stmts[i]
+		if isJump(s) {
+			return
+		}
+		if _, ok := s.(*pe.Label); !ok {
+			pos = s.Position()
+			break
+		}
+	}
+	l.report(obj, pos, ruleFallthrough, "the program falls through the end of the code")
+}
@@ -0,0 +1,110 @@
+package list
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+// DefaultFields is the fields listed when no fields are given
+var DefaultFields = []string{"DeviceId", "Name", "Type"}
+
+// profile is a built in set of fields for a group of object types
+type profile struct {
+	types  []string
+	fields []string
+}
+
+// profiles are the built in field sets selected with the --profile flag.
+// The fields can include wildcards and are expanded with the properties
+// found in the results.
+var profiles = map[string]profile{
+	"inputs": {
+		types:  []string{"InfinityInput"},
+		fields: []string{"DeviceId", "Name", "Channel", "ElecType", "ElecScale*", "EngScale*", "Format", "*Units*"},
+	},
+	"outputs": {
+		types:  []string{"InfinityOutput"},
+		fields: []string{"DeviceId", "Name", "Channel", "ElecType", "ElecScale*", "EngScale*", "Format", "*Units*"},
+	},
+	"numerics": {
+		types:  []string{"InfinityNumeric"},
+		fields: []string{"DeviceId", "Name", "*Value*", "Format", "*Units*"},
+	},
+	"programs": {
+		types:  slices.Clone(dmp.CodeTypes),
+		fields: []string{"DeviceId", "Name", "Type", "*Flow*", "*Auto*", "*State*", "LastChange"},
+	},
+	"alarms": {
+		types:  []string{"AlarmEnrollment"},
+		fields: []string{"DeviceId", "Name", "*Alarm*", "*Priority*", "*Limit*", "*Delay*"},
+	},
+}
+
+// profileNames returns the sorted names of the built in profiles
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for k := range profiles {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isWildcard tests if the field is a pattern
+func isWildcard(field string) bool {
+	return strings.Contains(field, "*")
+}
+
+// matchField tests the property name against the wildcard pattern.
+// The '*' matches any sequence of characters and the match is not
+// case sensitive.
+func matchField(pattern string, name string) bool {
+	return isLike(name, strings.ReplaceAll(pattern, "*", "%"))
+}
+
+// resolveFields expands the field list.
+//
+// Fields starting with '+' are appended to the base fields. If only
+// appended fields are given, or no fields at all, the base fields are
+// used. Wildcard fields are replaced with the matching names from
+// the available properties. Duplicate fields are removed.
+func resolveFields(fields []string, base []string, available []string) []string {
+
+	appendOnly := !slices.ContainsFunc(fields, func(f string) bool {
+		f = strings.TrimSpace(f)
+		return f != "" && !strings.HasPrefix(f, "+")
+	})
+
+	input := make([]string, 0, len(fields)+len(base))
+	if appendOnly {
+		input = append(input, base...)
+	}
+	for _, f := range fields {
+		f = strings.TrimSpace(strings.TrimPrefix(f, "+"))
+		if f != "" {
+			input = append(input, f)
+		}
+	}
+
+	result := make([]string, 0, len(input))
+	add := func(f string) {
+		if !slices.Contains(result, f) {
+			result = append(result, f)
+		}
+	}
+	for _, f := range input {
+		if !isWildcard(f) {
+			add(f)
+			continue
+		}
+		for _, name := range available {
+			if matchField(f, name) {
+				add(name)
+			}
+		}
+	}
+	return result
+}
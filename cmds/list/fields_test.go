@@ -0,0 +1,107 @@
+package list
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchField(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"Elec*", "ElecType", true},
+		{"Elec*", "ElecScaleTop", true},
+		{"Elec*", "EngScaleTop", false},
+		{"*Scale*", "ElecScaleTop", true},
+		{"*Scale*", "EngScaleBottom", true},
+		{"*Scale*", "Channel", false},
+		{"*top", "ElecScaleTop", true},
+		{"*", "Channel", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			if result := matchField(test.pattern, test.name); result != test.expected {
+				t.Errorf("expected %v, but got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestResolveFields(t *testing.T) {
+
+	available := []string{
+		"Channel",
+		"DeviceId",
+		"ElecScaleBottom",
+		"ElecScaleTop",
+		"ElecType",
+		"EngScaleBottom",
+		"EngScaleTop",
+		"Name",
+		"Type",
+	}
+
+	tests := []struct {
+		name     string
+		fields   []string
+		expected []string
+	}{
+		{
+			name:     "no fields",
+			fields:   nil,
+			expected: DefaultFields,
+		},
+		{
+			name:     "empty field",
+			fields:   []string{""},
+			expected: DefaultFields,
+		},
+		{
+			name:     "plain fields",
+			fields:   []string{"Name", "Channel"},
+			expected: []string{"Name", "Channel"},
+		},
+		{
+			name:     "append to defaults",
+			fields:   []string{"+Channel"},
+			expected: []string{"DeviceId", "Name", "Type", "Channel"},
+		},
+		{
+			name:     "append duplicate",
+			fields:   []string{"+Name", "+Channel"},
+			expected: []string{"DeviceId", "Name", "Type", "Channel"},
+		},
+		{
+			name:     "prefix wildcard",
+			fields:   []string{"Name", "Elec*"},
+			expected: []string{"Name", "ElecScaleBottom", "ElecScaleTop", "ElecType"},
+		},
+		{
+			name:     "contains wildcard",
+			fields:   []string{"*Scale*"},
+			expected: []string{"ElecScaleBottom", "ElecScaleTop", "EngScaleBottom", "EngScaleTop"},
+		},
+		{
+			name:     "append wildcard",
+			fields:   []string{"+EngScale*"},
+			expected: []string{"DeviceId", "Name", "Type", "EngScaleBottom", "EngScaleTop"},
+		},
+		{
+			name:     "unmatched wildcard",
+			fields:   []string{"Name", "Foo*"},
+			expected: []string{"Name"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := resolveFields(test.fields, DefaultFields, available)
+			if !slices.Equal(result, test.expected) {
+				t.Errorf("expected %v, but got %v", test.expected, result)
+			}
+		})
+	}
+}
@@ -1,17 +1,13 @@
 package list
 
 import (
-	"encoding/csv"
 	"fmt"
-	"log"
-	"os"
-	"path"
 	"slices"
 	"sort"
 	"strings"
 
 	"github.com/tpacheco/dmptool/dmp"
-	"github.com/xuri/excelize/v2"
+	"github.com/tpacheco/dmptool/internal/output"
 )
 
 type listHandler struct {
@@ -24,11 +20,6 @@ type listHandler struct {
 	whereExp expression
 }
 
-const (
-	csvExt  = ".csv"
-	xlsxExt = ".xlsx"
-)
-
 func (h *listHandler) Object(do *dmp.Object) {
 
 	if len(h.types) > 0 && !slices.Contains(h.types, do.Type) {
@@ -63,6 +54,30 @@ type Command struct {
 	Names    []string
 	Devices  []string
 	Ordering []string
+	Explain  bool
+	Profile  string
+	Format   string
+	SheetBy  string
+
+	// Defaults is the fields used when no fields are given or
+	// fields are only appended. If empty DefaultFields is used.
+	Defaults []string
+
+	// Headers maps the field names to the column headers used
+	// in the output. Fields without a header use the field name.
+	Headers map[string]string
+}
+
+// header returns the column headers for the fields.
+func (cmd *Command) header() []string {
+	header := make([]string, len(cmd.Fields))
+	for i, f := range cmd.Fields {
+		header[i] = f
+		if h, ok := cmd.Headers[f]; ok && h != "" {
+			header[i] = h
+		}
+	}
+	return header
 }
 
 func (cmd *Command) Execute() {
@@ -74,57 +89,88 @@ func (cmd *Command) Execute() {
 		types:   cmd.Types,
 	}
 
+	base := cmd.Defaults
+	if len(base) == 0 {
+		base = DefaultFields
+	}
+	if cmd.Profile != "" {
+		pf, ok := profiles[cmd.Profile]
+		if !ok {
+			fmt.Printf("unknown profile %q, expected one of %s\n", cmd.Profile, strings.Join(profileNames(), ", "))
+			return
+		}
+		base = pf.fields
+		if len(h.types) == 0 {
+			h.types = pf.types
+		}
+	}
+
 	if cmd.Filter != "" {
 		h.whereExp = parseWhere(cmd.Filter)
+		if _, ok := h.whereExp.(errOp); ok {
+			// the error is printed by parseWhere
+			return
+		}
 	}
 
-	dmp.ParseFile(cmd.FileName, h)
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	// if no fields are given then display the fields
-	// or if first field is *, -, or ? then display the
-	// fields
-	switch len(cmd.Fields) {
-	case 0:
-		processFields(h)
+	if cmd.Explain {
+		// show how the where filter was parsed
+		if h.whereExp == nil {
+			fmt.Println("no filter, use --where to give one")
+			return
+		}
+		fmt.Println(h.whereExp)
 		return
-	case 1:
+	}
+
+	dmp.ParseFile(cmd.FileName, h)
+
+	// if the first field is - or ? then display the fields
+	if len(cmd.Fields) == 1 {
 		switch cmd.Fields[0] {
-		case "?", "-", "*":
+		case "?", "-":
 			processFields(h)
 			return
 		}
 	}
 
+	cmd.Fields = resolveFields(cmd.Fields, base, listFields(h))
+	if len(cmd.Fields) == 0 {
+		fmt.Println("no fields matched")
+		return
+	}
+
 	table := buildTable(cmd, h)
 
 	if len(cmd.Ordering) > 0 {
-		err := reorder(cmd.Ordering, cmd.Fields, table)
+		err := output.Reorder(cmd.Ordering, cmd.Fields, table)
 		if err != nil {
 			fmt.Printf("could not reorder results: %s", err)
 			return
 		}
 	}
 
-	switch strings.ToLower(path.Ext(cmd.OutFile)) {
-	case xlsxExt:
-		writeXlsx(cmd.OutFile, cmd, table)
-	case csvExt:
-		writeCSV(cmd.OutFile, cmd, table)
-	default:
-		w := os.Stdout
-		if cmd.OutFile != "" {
-			f, err := os.Create(cmd.OutFile)
-			if err != nil {
-				fmt.Println("could not create file")
-				return
+	t := &output.Table{
+		Header: cmd.header(),
+		Rows:   table,
+	}
+	if cmd.SheetBy != "" {
+		// the sheet column is a field name so use its header
+		t.SheetBy = cmd.SheetBy
+		for i, f := range cmd.Fields {
+			if strings.EqualFold(f, cmd.SheetBy) {
+				t.SheetBy = t.Header[i]
 			}
-			defer func() {
-				f.Sync()
-				f.Close()
-			}()
-			w = f
 		}
-		writeFile(w, cmd, table)
+	}
+	if err := output.WriteFile(cmd.OutFile, format, t); err != nil {
+		fmt.Println(err)
 	}
 }
 
@@ -165,104 +211,3 @@ func buildTable(cmd *Command, h *listHandler) [][]string {
 	}
 	return table
 }
-
-func writeFile(w *os.File, cmd *Command, table [][]string) {
-
-	cols := len(cmd.Fields)
-
-	ws := widths(cmd, table)
-
-	formats := make([]string, cols)
-	for i, w := range ws {
-		if i > 0 {
-			formats[i] = fmt.Sprintf("  %%-%ds", w)
-		} else {
-			formats[i] = fmt.Sprintf("%%-%ds", w)
-		}
-	}
-
-	for i, t := range cmd.Fields {
-		fmt.Fprintf(w, formats[i], t)
-	}
-	fmt.Fprintln(w)
-
-	for i := range cmd.Fields {
-		fmt.Fprintf(w, formats[i], strings.Repeat("-", ws[i]))
-	}
-	fmt.Fprintln(w)
-
-	for _, row := range table {
-		for i, t := range row {
-			fmt.Fprintf(w, formats[i], t)
-		}
-		fmt.Fprintln(w)
-	}
-}
-
-func widths(cmd *Command, table [][]string) []int {
-	cols := len(cmd.Fields)
-	ws := make([]int, cols)
-	for i, n := range cmd.Fields {
-		if ws[i] < len(n) {
-			ws[i] = len(n)
-		}
-	}
-	for _, row := range table {
-		for i, n := range row {
-			if ws[i] < len(n) {
-				ws[i] = len(n)
-			}
-		}
-	}
-	return ws
-}
-
-func writeCSV(fileName string, cmd *Command, table [][]string) {
-
-	w, err := os.Create(fileName)
-	if err != nil {
-		fmt.Println("could not create file")
-		return
-	}
-	defer func() {
-		w.Sync()
-		w.Close()
-	}()
-
-	csvW := csv.NewWriter(w)
-	if err := csvW.Write(cmd.Fields); err != nil {
-		log.Fatalln("error writing record to csv:", err)
-	}
-
-	for _, row := range table {
-		if err := csvW.Write(row); err != nil {
-			log.Fatalln("error writing record to csv:", err)
-		}
-	}
-	csvW.Flush()
-}
-
-func writeXlsx(fName string, cmd *Command, table [][]string) {
-
-	ws := widths(cmd, table)
-
-	f := excelize.NewFile()
-	defer func() {
-		if err := f.Close(); err != nil {
-			fmt.Println(err)
-		}
-	}()
-
-	for i, col := range ws {
-		f.SetColWidth("Sheet1", fmt.Sprintf("%c", 'A'+i), fmt.Sprintf("%c", 'A'+i), float64(col))
-	}
-
-	f.SetSheetRow("Sheet1", "A1", &cmd.Fields)
-	for i, row := range table {
-		f.SetSheetRow("Sheet1", fmt.Sprintf("%c%d", 'A', i+2), &row)
-	}
-
-	if err := f.SaveAs(fName); err != nil {
-		fmt.Println(err)
-	}
-}
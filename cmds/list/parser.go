@@ -2,195 +2,343 @@ package list
 
 import "fmt"
 
-func parseList(tks []token) (int, []expression) {
-	if len(tks) == 0 {
-		return 0, nil
+// operator precedence levels for the where expression, from the
+// loosest binding to the tightest.
+const (
+	prec_none = iota
+	prec_or
+	prec_and
+	prec_compare
+)
+
+// precedence returns the binding strength of a binary operator kind,
+// or prec_none if the kind is not a binary operator.
+func precedence(k kind) int {
+	switch k {
+	case k_or:
+		return prec_or
+	case k_and:
+		return prec_and
+	case k_eq, k_ne, k_lt, k_le, k_gt, k_ge,
+		k_like, k_in, k_between, k_is, k_is_null, k_is_not_null:
+		return prec_compare
+	default:
+		return prec_none
 	}
-	if tks[0].kind != k_paren_left {
-		return 0, nil
+}
+
+// parser is a precedence climbing parser for the where expression.
+//
+//	expr      = term { ( OR | AND ) term }          (AND binds tighter)
+//	term      = NOT term | predicate
+//	predicate = operand [ compare operand
+//	                    | [NOT] LIKE pattern
+//	                    | [NOT] IN list
+//	                    | [NOT] BETWEEN operand AND operand
+//	                    | ISNULL | ISNOTNULL | IS [NOT] NULL ]
+//	operand   = field | string | integer | decimal | pattern | "(" expr ")"
+type parser struct {
+	tks []token
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tks)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
 	}
-	list := make([]expression, 0)
-	n := 1
-	for n < len(tks) {
-		switch tks[n].kind {
-		case k_paren_right:
-			n++
-			return n, list
-		case k_comma:
-			n++
-			continue
-		default:
-			m, item := parse(tks[n:])
-			n += m
-			list = append(list, item)
+	return p.tks[p.pos]
+}
+
+// peekAt returns the token n positions ahead of the current token.
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tks) {
+		return token{}
+	}
+	return p.tks[p.pos+n]
+}
+
+// offset is the position in the source of the current token
+// used for error reporting.
+func (p *parser) offset() int {
+	if p.done() {
+		if len(p.tks) == 0 {
+			return 0
 		}
+		last := p.tks[len(p.tks)-1]
+		return last.pos + len(last.text)
 	}
-	return n, nil
+	return p.tks[p.pos].pos
 }
 
-func parse(tks []token) (int, expression) {
-	var last expression
-	inParen := false
-	for i := 0; i < len(tks); {
-		pos := tks[i].pos
-		switch k := tks[i].kind; k {
+func (p *parser) fail(msg string) errOp {
+	return errOp{offset: p.offset(), msg: msg}
+}
 
-		case k_comma:
-			return i, last
+// expr parses a logical expression with all operators binding at
+// least as tight as minPrec. Operators of the same precedence are
+// left associative.
+func (p *parser) expr(minPrec int) expression {
+	lhs := p.term()
+	if _, ok := lhs.(errOp); ok {
+		return lhs
+	}
+	for !p.done() {
+		k := p.peek().kind
+		if !k.match(k_and, k_or) {
+			break
+		}
+		prec := precedence(k)
+		if prec < minPrec {
+			break
+		}
+		p.pos++
+		rhs := p.expr(prec + 1)
+		if _, ok := rhs.(errOp); ok {
+			return rhs
+		}
+		lhs = binOp{kind: k, lv: lhs, rv: rhs}
+	}
+	return lhs
+}
 
-		case k_integer, k_decimal:
-			last = tks[i]
-			i++
-			continue
+// term parses a predicate optionally negated by NOT.
+func (p *parser) term() expression {
+	if p.peek().kind == k_not {
+		p.pos++
+		if p.done() {
+			return p.fail("expected expression after not")
+		}
+		rv := p.term()
+		if _, ok := rv.(errOp); ok {
+			return rv
+		}
+		return uniOp{kind: k_not, rv: rv}
+	}
+	return p.predicate()
+}
 
-		case k_field, k_string:
-			last = tks[i]
-			i++
-			continue
+// operand parses a value or a parenthesized expression.
+func (p *parser) operand() expression {
+	if p.done() {
+		return p.fail("unexpected end of expression")
+	}
+	tk := p.peek()
+	switch tk.kind {
 
-		case k_paren_left:
-			inParen = true
-			var n int
-			i++
-			n, last = parse(tks[i:])
-			i += n
+	case k_field, k_string, k_integer, k_decimal, k_pattern:
+		p.pos++
+		return tk
 
-		case k_paren_right:
-			if !inParen {
-				return i, last
-			}
-			i++
-			if last == nil {
-				return i, errOp{offset: pos}
-			}
-			inParen = false
+	case k_paren_left:
+		p.pos++
+		ex := p.expr(prec_or)
+		if _, ok := ex.(errOp); ok {
+			return ex
+		}
+		if p.peek().kind != k_paren_right {
+			return p.fail("expected )")
+		}
+		p.pos++
+		return ex
 
-		case k_in:
-			i++
-			if last == nil || i >= len(tks) {
-				return i, errOp{offset: pos}
-			}
-			n, items := parseList(tks[i:])
-			i += n
-			last = inOp{
-				lv:    last,
-				items: items,
-			}
+	default:
+		return p.fail(fmt.Sprintf("unexpected %s", tk))
+	}
+}
 
-		case k_between:
-			i++
-			if last == nil || i >= len(tks) {
-				return i, errOp{offset: pos}
-			}
-			if len(tks)-i < 3 {
-				return i, errOp{offset: pos}
-			}
+// predicate parses an operand followed by an optional comparison.
+func (p *parser) predicate() expression {
+	lv := p.operand()
+	if _, ok := lv.(errOp); ok {
+		return lv
+	}
 
-			if tks[i+1].kind == k_and &&
-				tks[i+0].kind.match(k_string, k_integer, k_decimal) &&
-				tks[i+2].kind.match(k_string, k_integer, k_decimal) {
-				last = betweenOp{
-					kind:  k_between,
-					test:  last,
-					begin: tks[i+0],
-					end:   tks[i+2],
-				}
-				i += 3
-				continue
-			}
+	negate := false
+	if p.peek().kind == k_not && p.peekAt(1).kind.match(k_in, k_like, k_between) {
+		negate = true
+		p.pos++
+	}
+
+	if p.done() || precedence(p.peek().kind) != prec_compare {
+		return lv
+	}
 
-			n, ex := parse(tks[i:])
-			i += n
+	tk := p.peek()
 
-			op, ok := ex.(binOp)
-			if !ok || op.kind != k_and {
-				return i, errOp{offset: pos}
-			}
+	// all comparisons are between a field or value and a value
+	lt, ok := lv.(token)
+	if !ok {
+		return p.fail(fmt.Sprintf("%s requires a field", tk.kind))
+	}
 
-			last = betweenOp{
-				kind:  k_between,
-				test:  last,
-				begin: op.lv,
-				end:   op.rv,
-			}
+	var ex expression
+	switch tk.kind {
 
-		case k_like:
-			i++
-			if last == nil || i >= len(tks) {
-				return i, errOp{offset: pos}
-			}
-			next := tks[i]
-			i++
-			switch next.kind {
-
-			case k_field, k_string, k_pattern:
-				last = binOp{
-					kind: k,
-					lv:   last,
-					rv:   next,
-				}
-
-			default:
-				return i, errOp{offset: pos}
-			}
+	case k_in:
+		p.pos++
+		ex = p.inList(lt)
 
-		case k_eq, k_ne, k_lt, k_le, k_gt, k_ge:
-			i++
-			if last == nil || i >= len(tks) {
-				return i, errOp{offset: pos}
-			}
-			next := tks[i]
-			i++
-			switch next.kind {
+	case k_between:
+		p.pos++
+		ex = p.between(lt)
 
-			case k_field, k_string, k_integer, k_decimal, k_null:
-				last = binOp{
-					kind: k,
-					lv:   last,
-					rv:   next,
-				}
+	case k_like:
+		p.pos++
+		next := p.peek()
+		if !next.kind.match(k_field, k_string, k_pattern) {
+			return p.fail("expected pattern after like")
+		}
+		p.pos++
+		ex = binOp{kind: k_like, lv: lt, rv: next}
 
-			default:
-				return i, errOp{offset: pos}
+	case k_eq, k_ne, k_lt, k_le, k_gt, k_ge:
+		p.pos++
+		next := p.peek()
+		if !next.kind.match(k_field, k_string, k_integer, k_decimal, k_null) {
+			return p.fail(fmt.Sprintf("expected value after %s", tk.kind))
+		}
+		p.pos++
+		ex = binOp{kind: tk.kind, lv: lt, rv: next}
 
-			}
+	case k_is_null, k_is_not_null:
+		p.pos++
+		if lt.kind != k_field {
+			return p.fail(fmt.Sprintf("%s requires a field", tk.kind))
+		}
+		ex = uniOp{kind: tk.kind, rv: lt}
 
-		case k_and, k_or:
-			i++
-			n, rest := parse(tks[i:])
-			i += n
-			return i, binOp{
-				kind: k,
-				lv:   last,
-				rv:   rest,
-			}
+	case k_is:
+		p.pos++
+		k := k_is_null
+		if p.peek().kind == k_not {
+			k = k_is_not_null
+			p.pos++
+		}
+		if p.peek().kind != k_null {
+			return p.fail("expected null after is")
+		}
+		p.pos++
+		if lt.kind != k_field {
+			return p.fail(fmt.Sprintf("%s requires a field", k))
+		}
+		ex = uniOp{kind: k, rv: lt}
 
-		case k_is_null, k_is_not_null:
-			i++
-			tk, ok := last.(token)
-			if !ok || tk.kind != k_field {
-				return i, errOp{offset: pos}
-			}
-			last = uniOp{kind: k, rv: tk}
+	default:
+		return p.fail(fmt.Sprintf("unexpected %s", tk))
+	}
+
+	if _, ok := ex.(errOp); ok {
+		return ex
+	}
 
-		case k_not:
-			i++
-			n, rest := parse(tks[i:])
-			i += n
-			return i, uniOp{kind: k, rv: rest}
+	// comparisons do not chain, a = b = c is an error
+	if precedence(p.peek().kind) == prec_compare {
+		return p.fail(fmt.Sprintf("unexpected %s", p.peek().kind))
+	}
+
+	if negate {
+		return uniOp{kind: k_not, rv: ex}
+	}
+	return ex
+}
 
+func (p *parser) between(lv token) expression {
+	begin := p.peek()
+	if !begin.kind.match(k_string, k_integer, k_decimal) {
+		return p.fail("expected value after between")
+	}
+	p.pos++
+	if p.peek().kind != k_and {
+		return p.fail("expected and in between")
+	}
+	p.pos++
+	end := p.peek()
+	if !end.kind.match(k_string, k_integer, k_decimal) {
+		return p.fail("expected value after and")
+	}
+	p.pos++
+	return betweenOp{
+		kind:  k_between,
+		test:  lv,
+		begin: begin,
+		end:   end,
+	}
+}
+
+func (p *parser) inList(lv token) expression {
+	items := p.list()
+	if items == nil {
+		return p.fail("expected list after in")
+	}
+	return inOp{
+		kind:  k_in,
+		lv:    lv,
+		items: items,
+	}
+}
+
+// list parses a parenthesized comma separated list of expressions.
+// A nil result indicates the list could not be parsed.
+func (p *parser) list() []expression {
+	if p.peek().kind != k_paren_left {
+		return nil
+	}
+	p.pos++
+	list := make([]expression, 0)
+	for !p.done() {
+		switch p.peek().kind {
+		case k_paren_right:
+			p.pos++
+			return list
+		case k_comma:
+			p.pos++
 		default:
-			return i, errOp{offset: pos}
+			item := p.expr(prec_or)
+			if _, ok := item.(errOp); ok {
+				return nil
+			}
+			list = append(list, item)
+			if !p.peek().kind.match(k_comma, k_paren_right) {
+				return nil
+			}
 		}
 	}
-	return len(tks), last
+	return nil
+}
+
+// parseList parses a list of expressions returning the number of
+// tokens consumed.
+func parseList(tks []token) (int, []expression) {
+	p := &parser{tks: tks}
+	list := p.list()
+	return p.pos, list
+}
+
+// parse parses a single expression from the tokens returning the
+// number of tokens consumed. Parsing stops at the first token that
+// cannot continue the expression.
+func parse(tks []token) (int, expression) {
+	p := &parser{tks: tks}
+	if p.done() {
+		return 0, nil
+	}
+	ex := p.expr(prec_or)
+	return p.pos, ex
 }
 
 func parseWhere(f string) expression {
 	tks := scan(f)
-	_, exp := parse(tks)
+	p := &parser{tks: tks}
+	exp := p.expr(prec_or)
+	if _, ok := exp.(errOp); !ok && !p.done() {
+		exp = p.fail(fmt.Sprintf("unexpected %s", p.peek()))
+	}
 	if op, ok := exp.(errOp); ok {
-		fmt.Printf("error parsing where at : %d\n", op.offset)
+		fmt.Printf("error parsing where at : %d: %s\n", op.offset, op.msg)
 	}
 	return exp
 }
@@ -183,22 +183,22 @@ func TestParseAndAnd(t *testing.T) {
 	expect := binOp{
 		kind: k_and,
 		lv: binOp{
-			kind: k_eq,
-			lv:   token{kind: k_field, text: "X"},
-			rv:   token{kind: k_string, text: "A"},
-		},
-		rv: binOp{
 			kind: k_and,
 			lv: binOp{
+				kind: k_eq,
+				lv:   token{kind: k_field, text: "X"},
+				rv:   token{kind: k_string, text: "A"},
+			},
+			rv: binOp{
 				kind: k_gt,
 				lv:   token{kind: k_field, text: "Y"},
 				rv:   token{kind: k_string, text: "B"},
 			},
-			rv: binOp{
-				kind: k_lt,
-				lv:   token{kind: k_field, text: "Z"},
-				rv:   token{kind: k_string, text: "C"},
-			},
+		},
+		rv: binOp{
+			kind: k_lt,
+			lv:   token{kind: k_field, text: "Z"},
+			rv:   token{kind: k_string, text: "C"},
 		},
 	}
 	tks := scan(input)
@@ -213,10 +213,10 @@ func TestParseAndAnd(t *testing.T) {
 	if expect.String() != resultOp.String() {
 		t.Errorf("expected %q items got %q.", expect, resultOp)
 	}
-	resultR, ok1 := resultOp.rv.(binOp)
-	expectR, ok2 := expect.rv.(binOp)
-	if !ok1 || !ok2 || expectR.String() != resultR.String() {
-		t.Errorf("expected %q items got %q.", expectR, resultR)
+	resultL, ok1 := resultOp.lv.(binOp)
+	expectL, ok2 := expect.lv.(binOp)
+	if !ok1 || !ok2 || expectL.String() != resultL.String() {
+		t.Errorf("expected %q items got %q.", expectL, resultL)
 	}
 }
 
@@ -418,3 +418,100 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "AND binds tighter than OR",
+			input:    "a = 1 OR b = 2 AND c = 3",
+			expected: "(field(a) = integer(1) or (field(b) = integer(2) and field(c) = integer(3)))",
+		},
+		{
+			name:     "AND before OR",
+			input:    "a = 1 AND b = 2 OR c = 3",
+			expected: "((field(a) = integer(1) and field(b) = integer(2)) or field(c) = integer(3))",
+		},
+		{
+			name:     "OR is left associative",
+			input:    "a = 1 OR b = 2 OR c = 3",
+			expected: "((field(a) = integer(1) or field(b) = integer(2)) or field(c) = integer(3))",
+		},
+		{
+			name:     "parentheses override precedence",
+			input:    "(a = 1 OR b = 2) AND c = 3",
+			expected: "((field(a) = integer(1) or field(b) = integer(2)) and field(c) = integer(3))",
+		},
+		{
+			name:     "NOT binds to the predicate",
+			input:    "NOT a = 1 AND b = 2",
+			expected: "(not field(a) = integer(1) and field(b) = integer(2))",
+		},
+		{
+			name:     "NOT of a group",
+			input:    "NOT (a = 1 OR b = 2)",
+			expected: "not (field(a) = integer(1) or field(b) = integer(2))",
+		},
+		{
+			name:     "NOT IN",
+			input:    "Type NOT IN ('A', 'B') OR c = 3",
+			expected: "(not field(Type) IN (string(A), string(B)) or field(c) = integer(3))",
+		},
+		{
+			name:     "NOT LIKE",
+			input:    "Name NOT LIKE 'x%'",
+			expected: "not field(Name) like string(x%)",
+		},
+		{
+			name:     "IS NOT NULL",
+			input:    "a IS NOT NULL AND b IS NULL",
+			expected: "(isnotnull field(a) and isnull field(b))",
+		},
+		{
+			name:     "BETWEEN inside OR",
+			input:    "a = 1 OR b BETWEEN 1 AND 5 AND c = 3",
+			expected: "(field(a) = integer(1) or (field(b) BETWEEN integer(1) AND integer(5) and field(c) = integer(3)))",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tks := scan(test.input)
+			n, result := parse(tks)
+			if n != len(tks) {
+				t.Errorf("did not read entire input, read %d of %d.", n, len(tks))
+			}
+			if result.String() != test.expected {
+				t.Errorf("expected %q, got %q.", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing value", "a ="},
+		{"missing close paren", "(a = 1"},
+		{"chained comparison", "a = 1 = 2"},
+		{"dangling and", "a = 1 AND"},
+		{"trailing tokens", "a = 1 b"},
+		{"between without and", "a BETWEEN 1 5"},
+		{"in without list", "a IN 'x'"},
+		{"comparison of group", "(a = 1) = 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := parseWhere(test.input)
+			if _, ok := result.(errOp); !ok {
+				t.Errorf("expected error got %q", result)
+			}
+		})
+	}
+}
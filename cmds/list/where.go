@@ -68,7 +68,12 @@ type binOp struct {
 	rv expression
 }
 
+// String returns the expression with the logical operations
+// parenthesized to show the order of evaluation.
 func (op binOp) String() string {
+	if op.kind.match(k_and, k_or) {
+		return fmt.Sprintf("(%s %s %s)", op.lv, op.kind, op.rv)
+	}
 	return fmt.Sprintf("%s %s %s", op.lv, op.kind, op.rv)
 }
 
@@ -150,9 +155,15 @@ func (op uniOp) match(do *dmp.Object) bool {
 	}
 }
 
+// errOp is the result of a failed parse
 type errOp struct {
 	kind
 	offset int
+	msg    string
+}
+
+func (op errOp) String() string {
+	return fmt.Sprintf("error at %d: %s", op.offset, op.msg)
 }
 
 func (op errOp) match(do *dmp.Object) bool {
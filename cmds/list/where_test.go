@@ -1,6 +1,10 @@
 package list
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
 
 func TestIsLike(t *testing.T) {
 
@@ -35,3 +39,41 @@ func TestIsLike(t *testing.T) {
 		})
 	}
 }
+
+func TestWhereMatch(t *testing.T) {
+
+	obj := &dmp.Object{
+		Name: "SAT",
+		Path: `Site\AHU1\SAT`,
+		Properties: map[string]string{
+			"Name":    "SAT",
+			"Type":    "InfinityInput",
+			"Channel": "3",
+		},
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"Type = 'InfinityOutput' OR Channel = 3 AND Name = 'SAT'", true},
+		{"Type = 'InfinityOutput' OR Channel = 4 AND Name = 'SAT'", false},
+		{"(Type = 'InfinityOutput' OR Channel = 3) AND Name = 'OAT'", false},
+		{"NOT Type = 'InfinityOutput' AND Channel = 3", true},
+		{"NOT (Type = 'InfinityInput' OR Channel = 4)", false},
+		{"Type NOT IN ('InfinityOutput', 'InfinityNumeric')", true},
+		{"Name NOT LIKE 'S%'", false},
+		{"Channel NOT BETWEEN 1 AND 2", true},
+		{"Format IS NULL AND Channel IS NOT NULL", true},
+		{"AHU1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			exp := parseWhere(test.input)
+			if result := exp.match(obj); result != test.expected {
+				t.Errorf("%s: expected %v, but got %v", exp, test.expected, result)
+			}
+		})
+	}
+}
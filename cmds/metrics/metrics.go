@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+	"github.com/tpacheco/dmptool/pe"
+)
+
+// fields are the columns of the report, the names used by the sort flag
+var fields = []string{
+	"Program",
+	"TypeName",
+	"Lines",
+	"Comments",
+	"CommentRatio",
+	"Labels",
+	"Gotos",
+	"Complexity",
+	"References",
+	"Locals",
+	"SyntaxErrors",
+}
+
+// metrics are the measures of the code of a program
+type metrics struct {
+	lines      int
+	comments   int
+	labels     int
+	gotos      int
+	complexity int
+	references int
+	locals     int
+	errors     int
+}
+
+type Command struct {
+	FileName string
+	OutFile  string
+	Format   string
+	SheetBy  string
+	Ordering []string
+}
+
+func (cmd *Command) Execute() {
+
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	x := dmp.NewIndex()
+	dmpPath := dmp.ParseFile(cmd.FileName, x)
+
+	table := make([][]string, 0)
+	for _, obj := range x.Objects() {
+		if !dmp.IsCode(obj.Type) {
+			continue
+		}
+		m := measure(obj.Properties["ByteCode"], func(ref string) bool {
+			return x.IsExternal(ref, obj.Path, dmpPath)
+		})
+		table = append(table, m.row(obj))
+	}
+
+	if len(cmd.Ordering) > 0 {
+		if err := output.Reorder(cmd.Ordering, fields, table); err != nil {
+			fmt.Printf("could not reorder results: %s\n", err)
+			return
+		}
+	}
+
+	t := &output.Table{
+		Header:  fields,
+		Rows:    table,
+		SheetBy: cmd.SheetBy,
+	}
+	if err := output.WriteFile(cmd.OutFile, format, t); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// measure returns the metrics of the code.
+//
+// Lines is the number of lines with code and Comments the number of lines
+// with a comment. The complexity is the cyclomatic complexity, one more
+// than the number of decisions: each If, Case, For, While, Repeat, And and
+// Or. References is the number of distinct paths that are external and
+// Locals the number of local variables declared. SyntaxErrors is the number
+// of statements that could not be parsed, the labels, gotos, decisions and
+// locals in them are not counted.
+func measure(code string, external func(ref string) bool) *metrics {
+	m := &metrics{complexity: 1}
+
+	codeLines := make(map[int]bool)
+	commentLines := make(map[int]bool)
+	refs := make(map[string]bool)
+	for _, tk := range pe.Scan(code) {
+		switch tk.Kind {
+		case pe.TokNewline:
+		case pe.TokComment:
+			commentLines[tk.Line] = true
+		default:
+			codeLines[tk.Line] = true
+			if tk.Kind == pe.TokIdent && strings.Contains(tk.Text, `\`) && external(tk.Text) {
+				refs[strings.ToLower(tk.Text)] = true
+			}
+		}
+	}
+	m.lines = len(codeLines)
+	m.comments = len(commentLines)
+	m.references = len(refs)
+
+	f, errs := pe.Parse(code)
+	m.errors = len(errs)
+	pe.Inspect(f, func(n pe.Node) bool {
+		switch n := n.(type) {
+		case *pe.Label:
+			m.labels++
+		case *pe.Goto:
+			m.gotos++
+		case *pe.Declare:
+			m.locals += len(n.Names)
+		case *pe.If, *pe.For, *pe.While, *pe.Repeat:
+			m.complexity++
+		case *pe.Case:
+			// Case Else is not a decision
+			if len(n.Values) > 0 {
+				m.complexity++
+			}
+		case *pe.Binary:
+			if strings.EqualFold(n.Op, "and") || strings.EqualFold(n.Op, "or") {
+				m.complexity++
+			}
+		}
+		return true
+	})
+	return m
+}
+
+// commentRatio is the comment lines per line of code
+func (m *metrics) commentRatio() string {
+	if m.lines == 0 {
+		return "0.00"
+	}
+	return strconv.FormatFloat(float64(m.comments)/float64(m.lines), 'f', 2, 64)
+}
+
+// row returns the metrics in the order of the fields
+func (m *metrics) row(obj *dmp.Object) []string {
+	return []string{
+		obj.Path,
+		obj.Type,
+		strconv.Itoa(m.lines),
+		strconv.Itoa(m.comments),
+		m.commentRatio(),
+		strconv.Itoa(m.labels),
+		strconv.Itoa(m.gotos),
+		strconv.Itoa(m.complexity),
+		strconv.Itoa(m.references),
+		strconv.Itoa(m.locals),
+		strconv.Itoa(m.errors),
+	}
+}
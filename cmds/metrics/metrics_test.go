@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+func TestMeasure(t *testing.T) {
+	x := dmp.NewIndex()
+	x.Path("NUSite")
+	x.Add(&dmp.Object{Name: "SAT", Path: `NUSite\Ctrl1\SAT`})
+	external := func(ref string) bool {
+		return x.IsExternal(ref, `NUSite\Ctrl1\Prog`, `NUSite\Ctrl1`)
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		expected metrics
+	}{
+		{
+			name:     "empty",
+			code:     "",
+			expected: metrics{complexity: 1},
+		},
+		{
+			name: "labels and gotos",
+			code: `' start up
+Numeric Tmp, Count
+Line Start
+  Tmp = NUSite\Ctrl1\SAT ' supply air
+  Goto Run
+Line Run
+  Count = Count + NUSite\Ctrl2\OAT + AHU1\Fan
+  Goto Start
+`,
+			expected: metrics{lines: 7, comments: 2, labels: 2, gotos: 2, complexity: 1, references: 1, locals: 2},
+		},
+		{
+			name: "decisions",
+			code: `If Tmp > 72 And Fan Is On Then
+  Fan = Off
+Else If Tmp < 65 Or Tmp > 80 Then Fan = On
+EndIf
+Select Case Mode
+Case 1, 2
+  Fan = On
+Case Else
+  Fan = Off
+EndSelect
+For I = 1 To 10
+  Sum = Sum + nusite\ctrl2\oat + NUSite\Ctrl2\OAT + NUSite\Ctrl1\SAT
+Next I
+While Tmp > 1
+  Tmp = Tmp - 1
+EndWhile
+`,
+			expected: metrics{lines: 16, complexity: 8, references: 1},
+		},
+		{
+			name: "syntax errors",
+			code: `If Tmp > 72 Then
+  Fan = On +
+EndIf
+If Tmp < 65 Then Fan = Off
+`,
+			expected: metrics{lines: 4, complexity: 3, errors: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := measure(test.code, external)
+			if *m != test.expected {
+				t.Errorf("measure() = %+v; want %+v", *m, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package pe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"gopkg.in/yaml.v3"
+)
+
+// formats of the object property files
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+var objectFormats = []string{formatJSON, formatYAML}
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+const (
+	// panelExt is the extension of the PanelObjectList of the graphics
+	panelExt = ".pan"
+
+	// dictionaryExt is the extension of the tables of the dictionaries
+	dictionaryExt = ".csv"
+
+	// typeDictionary is the type name used to filter the dictionaries
+	typeDictionary = "Dictionary"
+)
+
+// marshalObject returns the object properties in the format, the keys of
+// the properties are sorted.
+func marshalObject(format string, v any) ([]byte, error) {
+	if format == formatYAML {
+		return yaml.Marshal(v)
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	return append(b, '\n'), err
+}
+
+// include tests if the type is exported by the types filter
+func (s *peHandler) include(typeName string) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, typeName)
+}
+
+// handlePanel writes the PanelObjectList of a graphics panel
+func (s *peHandler) handlePanel(obj *dmp.Object, panel string) {
+	file := s.fileName(obj, panelExt)
+	b := []byte(panel)
+	if err := s.writeFile(file, b, obj); err != nil {
+		fmt.Printf("error writing %s: %s\n", file, err)
+		return
+	}
+	s.entries = append(s.entries, newEntry(obj, s.destDir, file, b))
+}
+
+// Dictionary writes the tables of the dictionary as csv files, a number
+// is added to the name when there is more than one table.
+func (s *peHandler) Dictionary(dict *dmp.Dictionary) {
+	if !s.objects || !s.include(typeDictionary) {
+		return
+	}
+	for i, table := range dict.Tables {
+		name := dict.Name
+		if len(dict.Tables) > 1 {
+			name += "_" + strconv.Itoa(i+1)
+		}
+		// the tables are laid out as objects of the Dictionary type
+		obj := &dmp.Object{
+			Name: name,
+			Path: filepath.Join(dict.Path, name),
+			Type: typeDictionary,
+		}
+		file := s.fileName(obj, dictionaryExt)
+
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		w.Write(table.Header)
+		w.WriteAll(table.Rows)
+
+		if err := s.writeFile(file, buf.Bytes(), nil); err != nil {
+			fmt.Printf("error writing %s: %s\n", file, err)
+			continue
+		}
+		s.entries = append(s.entries, &entry{
+			File:     s.relPath(file),
+			Path:     obj.Path,
+			Type:     typeDictionary,
+			Checksum: checksum(buf.Bytes()),
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package pe
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const objectsDump = `Path : NUSite
+Dictionary : Ctrl1
+'TYPE : Name : Id
+InfinityInput : SAT : 1
+InfinityInput : OAT : 2
+
+EndDictionary
+BeginController : Ctrl1
+Object : SAT
+Type : InfinityInput
+Channel : 1
+EndObject
+Object : Panel
+Type : Graphics
+PanelObjectList
+  Type : Value  X : 10  Path : SAT
+}
+EndObject
+Object : Prog
+Type : InfinityProgram
+ByteCode
+x = 1
+EndByteCode
+EndObject
+EndController
+`
+
+func TestExport(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		format   string
+		types    []string
+		expected []string
+	}{
+		{"json", "json", nil, []string{
+			"NUSite/Ctrl1/Ctrl1.csv",
+			"NUSite/Ctrl1/Panel.json",
+			"NUSite/Ctrl1/Panel.pan",
+			"NUSite/Ctrl1/Prog.json",
+			"NUSite/Ctrl1/Prog.pe",
+			"NUSite/Ctrl1/SAT.json",
+		}},
+		{"yaml", "YAML", []string{"InfinityInput"}, []string{
+			"NUSite/Ctrl1/SAT.yaml",
+		}},
+		{"dictionary", "json", []string{"Dictionary"}, []string{
+			"NUSite/Ctrl1/Ctrl1.csv",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dumpFile := filepath.Join(dir, "site.dmp")
+			outDir := filepath.Join(dir, "out")
+			os.WriteFile(dumpFile, []byte(objectsDump), 0o644)
+
+			(&Command{FileName: dumpFile, OutDir: outDir, Objects: true, Format: tt.format, Types: tt.types}).Execute()
+
+			files := make([]string, 0)
+			filepath.WalkDir(outDir, func(name string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(outDir, name)
+					files = append(files, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			if strings.Join(files, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v got %v", tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestExportDictionaryLayout(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	outDir := filepath.Join(dir, "out")
+	os.WriteFile(dumpFile, []byte(objectsDump), 0o644)
+
+	(&Command{
+		FileName:   dumpFile,
+		OutDir:     outDir,
+		Objects:    true,
+		Types:      []string{"Dictionary"},
+		TypeFolder: true,
+		TypePrefix: "__",
+		Flatten:    true,
+		FlattenSep: "~",
+	}).Execute()
+
+	expected := filepath.Join(outDir, "NUSite~Ctrl1~__Dictionary~Ctrl1.csv")
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("expected the dictionary at %s: %s", expected, err)
+	}
+}
+
+func TestExportDictionaryTables(t *testing.T) {
+	dir := t.TempDir()
+	s := &peHandler{destDir: dir, objects: true, written: make(map[string]bool)}
+	table := &dmp.Table{Header: []string{"TYPE", "Name"}, Rows: [][]string{{"InfinityInput", "SAT"}}}
+	s.Dictionary(&dmp.Dictionary{
+		Name:   "Ctrl1",
+		Path:   filepath.Join("NUSite", "Ctrl1"),
+		Tables: []*dmp.Table{table, table},
+	})
+
+	expected := []string{
+		filepath.Join("NUSite", "Ctrl1", "Ctrl1_1") + " NUSite/Ctrl1/Ctrl1_1.csv",
+		filepath.Join("NUSite", "Ctrl1", "Ctrl1_2") + " NUSite/Ctrl1/Ctrl1_2.csv",
+	}
+	got := make([]string, len(s.entries))
+	for i, e := range s.entries {
+		got[i] = e.Path + " " + e.File
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v got %v", expected, got)
+	}
+}
+
+func TestExportContent(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	os.WriteFile(dumpFile, []byte(objectsDump), 0o644)
+	(&Command{FileName: dumpFile, OutDir: dir, Objects: true, Format: "yaml"}).Execute()
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"NUSite/Ctrl1/Ctrl1.csv", "TYPE,Name,Id\nInfinityInput,SAT,1\nInfinityInput,OAT,2\n"},
+		{"NUSite/Ctrl1/Panel.pan", "  Type : Value  X : 10  Path : SAT\n}"},
+		{"NUSite/Ctrl1/SAT.yaml", "path: NUSite/Ctrl1/SAT\ntype: InfinityInput\nproperties:\n    Channel: \"1\"\n    Name: SAT\n    Type: InfinityInput\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.file)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.ReplaceAll(string(b), `\`, "/"); got != tt.expected {
+				t.Errorf("expected\n%q\ngot\n%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExportFormat(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	os.WriteFile(dumpFile, []byte(objectsDump), 0o644)
+	(&Command{FileName: dumpFile, OutDir: filepath.Join(dir, "out"), Objects: true, Format: "xml"}).Execute()
+	if _, err := os.Stat(filepath.Join(dir, "out")); err == nil {
+		t.Error("expected nothing exported for an unknown format")
+	}
+}
@@ -0,0 +1,207 @@
+package pe
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// import status of the programs and files
+const (
+	statusUpdated   = "updated"
+	statusRemoved   = "removed"
+	statusAdded     = "added"
+	statusUnmatched = "unmatched"
+)
+
+// imported is a program or a file found by the import
+type imported struct {
+	status  string
+	program string
+	file    string
+}
+
+type ImportCommand struct {
+	FileName   string
+	Dir        string
+	OutFile    string
+	TypeFolder bool
+	Flatten    bool
+	FlattenSep string
+	TypePrefix string
+}
+
+// fileKey is the key of the file name, file names are not case sensitive
+// on windows.
+func fileKey(name string) string {
+	return strings.ToLower(filepath.Clean(name))
+}
+
+// readFiles returns the program files in the directory by the file key
+func readFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(name), ".pe") {
+			files[fileKey(name)] = name
+		}
+		return nil
+	})
+	return files, err
+}
+
+// readCode returns the code of the file as it is kept in the ByteCode,
+// with \n line endings. A final line ending added by an editor is removed
+// when the original code does not have one.
+func readCode(name string, original string) (string, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	code := strings.ReplaceAll(string(b), "\r\n", "\n")
+	if !strings.HasSuffix(original, "\n") {
+		code = strings.TrimSuffix(code, "\n")
+	}
+	return code, nil
+}
+
+// Execute writes a copy of the dump with the ByteCode of the programs
+// replaced by the files in the directory, and reports the programs
+// updated, the programs without a file, and the files without a program.
+func (cmd *ImportCommand) Execute() {
+
+	if cmd.OutFile == "" {
+		fmt.Println("an output dump file is required")
+		return
+	}
+
+	h := &peHandler{
+		destDir:    cmd.Dir,
+		withType:   cmd.TypeFolder,
+		flatten:    cmd.Flatten,
+		typePrefix: cmd.TypePrefix,
+		separator:  cmd.FlattenSep,
+	}
+
+	files, err := readFiles(cmd.Dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	x := dmp.NewIndex()
+	dmp.ParseFile(cmd.FileName, x)
+
+	results, changes := h.match(x, files)
+	if err := cmd.rewrite(x, changes); err != nil {
+		fmt.Println(err)
+		return
+	}
+	writeReport(results)
+}
+
+// match matches the programs of the dump to the files, and returns the
+// results and the changes to the programs updated.
+func (h *peHandler) match(x *dmp.Index, files map[string]string) ([]*imported, map[*dmp.Object]map[string]string) {
+	// programs mapped to the same file can not be told apart
+	programs := make(map[string][]*dmp.Object)
+	for _, obj := range x.Objects() {
+		if dmp.IsCode(obj.Type) {
+			key := fileKey(h.filePath(obj))
+			programs[key] = append(programs[key], obj)
+		}
+	}
+
+	results := make([]*imported, 0)
+	changes := make(map[*dmp.Object]map[string]string)
+	for _, obj := range x.Objects() {
+		if !dmp.IsCode(obj.Type) {
+			continue
+		}
+		key := fileKey(h.filePath(obj))
+		name, ok := files[key]
+		switch {
+		case !ok:
+			results = append(results, &imported{statusRemoved, obj.Path, ""})
+			continue
+		case len(programs[key]) > 1:
+			results = append(results, &imported{statusUnmatched, obj.Path, name})
+			continue
+		}
+		code, err := readCode(name, obj.Properties["ByteCode"])
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if code == obj.Properties["ByteCode"] {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		changes[obj] = map[string]string{
+			"ByteCode":   code,
+			"LastChange": dmp.FormatTime(info.ModTime()),
+		}
+		results = append(results, &imported{statusUpdated, obj.Path, name})
+	}
+	added := make([]string, 0)
+	for key, name := range files {
+		if _, ok := programs[key]; !ok {
+			added = append(added, name)
+		}
+	}
+	slices.Sort(added)
+	for _, name := range added {
+		results = append(results, &imported{statusAdded, "", name})
+	}
+
+	return results, changes
+}
+
+// rewrite writes the dump with the changes to the output file. The
+// objects of the rewrite are found by their path in the index.
+func (cmd *ImportCommand) rewrite(x *dmp.Index, changes map[*dmp.Object]map[string]string) error {
+	r, err := os.Open(cmd.FileName)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// the dump is written after it is read, the output can be the same file
+	buf := &bytes.Buffer{}
+	err = dmp.Rewrite(r, buf, func(obj *dmp.Object) map[string]string {
+		return changes[x.Lookup(obj.Path)]
+	})
+	if err != nil {
+		return err
+	}
+	r.Close()
+	return os.WriteFile(cmd.OutFile, buf.Bytes(), 0o644)
+}
+
+func writeReport(results []*imported) {
+	if len(results) == 0 {
+		fmt.Println("No programs changed.")
+		return
+	}
+	table := &output.Table{
+		Header: []string{"Status", "Program", "File"},
+		Rows:   make([][]string, 0, len(results)),
+	}
+	for _, r := range results {
+		table.Rows = append(table.Rows, []string{r.status, r.program, r.file})
+	}
+	output.Write(os.Stdout, output.Text, table)
+}
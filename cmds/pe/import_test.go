@@ -0,0 +1,114 @@
+package pe
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const importDump = `Path : NUSite
+BeginController : Ctrl1
+Object : Edited
+Type : InfinityProgram
+LastChange : 1/4/2024 3:04:05 PM
+ByteCode
+x = 1
+EndByteCode
+EndObject
+Object : Same
+Type : InfinityProgram
+ByteCode
+y = 2
+EndByteCode
+EndObject
+Object : Deleted
+Type : InfinityFunction
+ByteCode
+Return 1
+EndByteCode
+EndObject
+EndController
+`
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	outFile := filepath.Join(dir, "new.dmp")
+	codeDir := filepath.Join(dir, "code")
+	if err := os.WriteFile(dumpFile, []byte(importDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	(&Command{FileName: dumpFile, OutDir: codeDir, TypeFolder: true, TypePrefix: "__"}).Execute()
+
+	h := &peHandler{destDir: codeDir, withType: true, typePrefix: "__"}
+	x := dmp.NewIndex()
+	dmp.ParseFile(dumpFile, x)
+	edited := h.filePath(x.Lookup(`NUSite\Ctrl1\Edited`))
+	deleted := h.filePath(x.Lookup(`NUSite\Ctrl1\Deleted`))
+	added := filepath.Join(filepath.Dir(edited), "New.pe")
+	os.WriteFile(edited, []byte("x = 3\r\n"), 0o644)
+	os.WriteFile(added, []byte("z = 1"), 0o644)
+	os.Remove(deleted)
+
+	files, err := readFiles(codeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, changes := h.match(x, files)
+
+	got := make([]string, len(results))
+	for i, r := range results {
+		got[i] = r.status + " " + filepath.Base(r.program) + " " + filepath.Base(r.file)
+	}
+	expected := "updated Edited Edited.pe,removed Deleted .,added . New.pe"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expected %s got %s", expected, strings.Join(got, ","))
+	}
+
+	cmd := &ImportCommand{FileName: dumpFile, OutFile: outFile}
+	if err := cmd.rewrite(x, changes); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(outFile)
+	out := string(b)
+	if !strings.Contains(out, "ByteCode\nx = 3\nEndByteCode") {
+		t.Errorf("expected the updated code got\n%s", out)
+	}
+	if strings.Contains(out, "1/4/2024 3:04:05 PM") {
+		t.Error("expected the LastChange to be updated")
+	}
+	if !strings.Contains(out, "ByteCode\nReturn 1\nEndByteCode") {
+		t.Error("expected the program without a file to be kept")
+	}
+}
+
+func TestImportInPlace(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	codeDir := filepath.Join(dir, "code")
+	if err := os.WriteFile(dumpFile, []byte(importDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	(&Command{FileName: dumpFile, OutDir: codeDir}).Execute()
+
+	h := &peHandler{destDir: codeDir}
+	x := dmp.NewIndex()
+	dmp.ParseFile(dumpFile, x)
+	os.WriteFile(h.filePath(x.Lookup(`NUSite\Ctrl1\Edited`)), []byte("x = 3"), 0o644)
+
+	(&ImportCommand{FileName: dumpFile, Dir: codeDir, OutFile: dumpFile}).Execute()
+
+	b, _ := os.ReadFile(dumpFile)
+	out := string(b)
+	if !strings.Contains(out, "ByteCode\nx = 3\nEndByteCode") {
+		t.Errorf("expected the updated code got\n%s", out)
+	}
+	if !strings.Contains(out, "ByteCode\ny = 2\nEndByteCode") {
+		t.Errorf("expected the rest of the dump to be kept got\n%s", out)
+	}
+}
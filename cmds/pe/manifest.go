@@ -0,0 +1,103 @@
+package pe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const (
+	// sidecarExt is added to the program file name for the sidecar file
+	sidecarExt = ".json"
+
+	// manifestName is the name of the manifest file in the output directory
+	manifestName = "manifest.json"
+)
+
+// entry is the metadata of an exported program, written to the sidecar
+// file and listed in the manifest.
+type entry struct {
+	File       string            `json:"file,omitempty" yaml:"file,omitempty"`
+	Path       string            `json:"path" yaml:"path"`
+	Type       string            `json:"type" yaml:"type"`
+	Checksum   string            `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Properties map[string]string `json:"properties" yaml:"properties"`
+}
+
+// manifest lists all the exported files. The files are sorted and there
+// are no timestamps, so the same dump gives the same manifest.
+type manifest struct {
+	Source string   `json:"source"`
+	Device string   `json:"device,omitempty"`
+	Files  []*entry `json:"files"`
+}
+
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// newEntry returns the metadata of the object written to the file, all
+// the properties except the code and the panel are kept.
+func newEntry(obj *dmp.Object, destDir string, file string, code []byte) *entry {
+	rel, err := filepath.Rel(destDir, file)
+	if err != nil {
+		rel = file
+	}
+	props := maps.Clone(obj.Properties)
+	delete(props, "ByteCode")
+	delete(props, "PanelObjectList")
+	return &entry{
+		File:       filepath.ToSlash(rel),
+		Path:       obj.Path,
+		Type:       obj.Type,
+		Checksum:   checksum(code),
+		Properties: props,
+	}
+}
+
+// marshal returns the indented json of the metadata, the keys of the
+// properties are sorted.
+func marshal(v any) []byte {
+	b, _ := json.MarshalIndent(v, "", "  ")
+	return append(b, '\n')
+}
+
+// writeManifest writes the manifest of the exported files to the directory
+func (s *peHandler) writeManifest(source string, device string) error {
+	files := slices.Clone(s.entries)
+	slices.SortFunc(files, func(a, b *entry) int {
+		return strings.Compare(a.File, b.File)
+	})
+	return s.writeFile(filepath.Join(s.destDir, manifestName), marshal(&manifest{
+		Source: source,
+		Device: device,
+		Files:  files,
+	}), nil)
+}
+
+// readManifest reads the manifest in the directory, nil if there is none
+func readManifest(destDir string) (*manifest, error) {
+	b, err := os.ReadFile(filepath.Join(destDir, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestName, err)
+	}
+	return m, nil
+}
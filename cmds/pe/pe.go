@@ -2,8 +2,10 @@ package pe
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/tpacheco/dmptool/dmp"
@@ -16,24 +18,25 @@ type peHandler struct {
 	separator  string
 	withType   bool
 	typePrefix string
+	sidecar    bool
+	objects    bool
+	format     string
+	types      []string
+	sync       bool
+	entries    []*entry
+	written    map[string]bool
+	changes    []*change
 }
 
-func isCodeType(typeName string) bool {
-	switch typeName {
-	case
-		"Program",
-		"InfinityProgram",
-		"InfinityFunction":
-		// ok
-		return true
-	default:
-		return false
-	}
+func (s *peHandler) filePath(obj *dmp.Object) string {
+	return s.fileName(obj, ".pe")
 }
 
-func (s *peHandler) filePath(obj *dmp.Object) string {
+// fileName returns the file of the object with the extension, in the
+// layout set by the type and flatten options.
+func (s *peHandler) fileName(obj *dmp.Object, ext string) string {
 
-	fileName := obj.Path + ".pe"
+	fileName := obj.Path + ext
 
 	if s.withType {
 		dir, name := filepath.Split(fileName)
@@ -42,7 +45,7 @@ func (s *peHandler) filePath(obj *dmp.Object) string {
 
 	if s.flatten {
 		fileName = strings.Join(
-			filepath.SplitList(fileName),
+			strings.Split(fileName, string(filepath.Separator)),
 			s.separator,
 		)
 	}
@@ -58,28 +61,64 @@ func (s *peHandler) handleCode(obj *dmp.Object) {
 	}
 
 	file := s.filePath(obj)
-	dirName := filepath.Dir(file)
+	if err := s.writeFile(file, []byte(code), obj); err != nil {
+		fmt.Printf("error writing %s: %s\n", file, err)
+		return
+	}
+
+	e := newEntry(obj, s.destDir, file, []byte(code))
+	s.entries = append(s.entries, e)
+	if s.sidecar {
+		if err := s.writeFile(file+sidecarExt, marshal(e), obj); err != nil {
+			fmt.Printf("error writing %s: %s\n", file+sidecarExt, err)
+		}
+	}
+}
+
+// handleObject writes the properties of an object that is not a program
+func (s *peHandler) handleObject(obj *dmp.Object) {
+	props := maps.Clone(obj.Properties)
+	delete(props, "ByteCode")
+	if panel, ok := props["PanelObjectList"]; ok {
+		delete(props, "PanelObjectList")
+		s.handlePanel(obj, panel)
+	}
 
-	err := os.MkdirAll(dirName, os.ModeDir)
+	file := s.fileName(obj, "."+s.format)
+	b, err := marshalObject(s.format, &entry{
+		Path:       obj.Path,
+		Type:       obj.Type,
+		Properties: props,
+	})
+	if err == nil {
+		err = s.writeFile(file, b, obj)
+	}
 	if err != nil {
-		fmt.Printf("error creating directory %s: %e\n", dirName, err)
+		fmt.Printf("error writing %s: %s\n", file, err)
 		return
 	}
+	s.entries = append(s.entries, newEntry(obj, s.destDir, file, b))
+}
 
-	os.WriteFile(file, []byte(code), os.ModePerm)
-
-	if mod, ok := obj.Properties["Modified"]; ok {
-		modTime, err := dmp.ParseTime(mod)
-		if err == nil {
-			os.Chtimes(file, modTime, modTime)
-		}
+// setModified sets the file times to the LastChange of the object
+func setModified(file string, obj *dmp.Object) {
+	if !obj.Modified.IsZero() {
+		os.Chtimes(file, obj.Modified, obj.Modified)
 	}
 }
 
 func (s *peHandler) Object(obj *dmp.Object) {
-	if isCodeType(obj.Type) {
+	if !s.include(obj.Type) {
+		return
+	}
+	code := dmp.IsCode(obj.Type)
+	if code {
 		s.handleCode(obj)
 	}
+	// the properties of the programs are in the sidecar when it is written
+	if s.objects && !(code && s.sidecar) {
+		s.handleObject(obj)
+	}
 }
 
 type Command struct {
@@ -89,6 +128,18 @@ type Command struct {
 	Flatten    bool
 	FlattenSep string
 	TypePrefix string
+	Sidecar    bool
+	Manifest   bool
+	// Objects writes the properties of the objects, the panels and the
+	// dictionaries
+	Objects bool
+	// Format is the format of the object property files, json or yaml
+	Format string
+	// Types limits the objects exported to the types
+	Types []string
+	// Sync writes only the changed files and removes the files of the
+	// previous manifest that are not exported.
+	Sync bool
 }
 
 func (cmd *Command) Execute() {
@@ -99,6 +150,20 @@ func (cmd *Command) Execute() {
 		flatten:    cmd.Flatten,
 		typePrefix: cmd.TypePrefix,
 		separator:  cmd.FlattenSep,
+		sidecar:    cmd.Sidecar,
+		objects:    cmd.Objects,
+		format:     strings.ToLower(cmd.Format),
+		types:      cmd.Types,
+		sync:       cmd.Sync,
+		written:    make(map[string]bool),
+	}
+
+	if h.format == "" {
+		h.format = formatJSON
+	}
+	if !slices.Contains(objectFormats, h.format) {
+		fmt.Printf("%s: %s, use %s\n", ErrUnknownFormat, cmd.Format, strings.Join(objectFormats, ", "))
+		return
 	}
 
 	if cmd.OutDir == "" {
@@ -110,5 +175,25 @@ func (cmd *Command) Execute() {
 		h.destDir = d
 	}
 
-	dmp.ParseFile(cmd.FileName, h)
+	var previous *manifest
+	if cmd.Sync {
+		var err error
+		if previous, err = readManifest(h.destDir); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	dmpPath := dmp.ParseFile(cmd.FileName, h)
+
+	if cmd.Manifest || cmd.Sync {
+		err := h.writeManifest(filepath.Base(cmd.FileName), dmpPath)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+	if cmd.Sync {
+		h.removeOrphans(previous)
+		h.writeChanges(os.Stdout)
+	}
 }
@@ -0,0 +1,158 @@
+package pe
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const exportDump = `Path : NUSite
+BeginController : Ctrl1
+Object : Zeta
+Type : InfinityProgram
+FlowType : Looping
+LastChange : 1/4/2024 3:04:05 PM
+ByteCode
+x = 1
+EndByteCode
+EndObject
+Object : Alpha
+Type : InfinityFunction
+ByteCode
+Return 1
+EndByteCode
+EndObject
+EndController
+`
+
+func TestExportMetadata(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	outDir := filepath.Join(dir, "code")
+	os.WriteFile(dumpFile, []byte(exportDump), 0o644)
+
+	(&Command{FileName: dumpFile, OutDir: outDir, Sidecar: true, Manifest: true}).Execute()
+
+	b, err := os.ReadFile(filepath.Join(outDir, manifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Source != "site.dmp" {
+		t.Errorf("expected the source site.dmp got %s", m.Source)
+	}
+	if len(m.Files) != 2 || m.Files[0].File != "NUSite/Ctrl1/Alpha.pe" || m.Files[1].File != "NUSite/Ctrl1/Zeta.pe" {
+		t.Fatalf("expected the files sorted got %v", m.Files)
+	}
+	zeta := m.Files[1]
+	if zeta.Checksum != checksum([]byte("x = 1")) {
+		t.Errorf("expected the checksum of the code got %s", zeta.Checksum)
+	}
+	if zeta.Properties["FlowType"] != "Looping" || zeta.Properties["ByteCode"] != "" {
+		t.Errorf("expected the properties without the code got %v", zeta.Properties)
+	}
+
+	file := filepath.Join(outDir, "NUSite", "Ctrl1", "Zeta.pe")
+	sidecar := &entry{}
+	b, err = os.ReadFile(file + sidecarExt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, sidecar); err != nil {
+		t.Fatal(err)
+	}
+	if sidecar.Type != "InfinityProgram" || sidecar.Checksum != zeta.Checksum {
+		t.Errorf("expected the sidecar to match the manifest got %v", sidecar)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified, _ := dmp.ParseTime("1/4/2024 3:04:05 PM")
+	if !info.ModTime().Equal(modified) {
+		t.Errorf("expected the file time %s got %s", modified, info.ModTime())
+	}
+	if info, _ := os.Stat(filepath.Join(outDir, "NUSite", "Ctrl1", "Alpha.pe")); info.ModTime().Before(time.Now().Add(-time.Hour)) {
+		t.Error("expected the time of a program without LastChange to be the time written")
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	outDir := filepath.Join(dir, "repo")
+	os.WriteFile(dumpFile, []byte(exportDump), 0o644)
+
+	sync := func() {
+		(&Command{FileName: dumpFile, OutDir: outDir, Sidecar: true, Objects: true, Sync: true}).Execute()
+	}
+	sync()
+
+	zeta := filepath.Join(outDir, "NUSite", "Ctrl1", "Zeta.pe")
+	alpha := filepath.Join(outDir, "NUSite", "Ctrl1", "Alpha.pe")
+	for _, name := range []string{zeta, zeta + sidecarExt, alpha, filepath.Join(outDir, manifestName)} {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// unchanged files are not written
+	mark := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	os.Chtimes(alpha, mark, mark)
+	sync()
+	if info, _ := os.Stat(alpha); !info.ModTime().Equal(mark) {
+		t.Error("expected the unchanged file not to be written")
+	}
+
+	// a program removed from the dump is removed with its sidecar
+	changed := strings.Replace(exportDump, "x = 1", "x = 2", 1)
+	changed = changed[:strings.Index(changed, "Object : Alpha")] + "EndController\n"
+	os.WriteFile(dumpFile, []byte(changed), 0o644)
+	sync()
+	if _, err := os.Stat(alpha); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected the removed program file to be removed")
+	}
+	if _, err := os.Stat(alpha + sidecarExt); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected the removed program sidecar to be removed")
+	}
+	if b, _ := os.ReadFile(zeta); string(b) != "x = 2" {
+		t.Errorf("expected the changed code got %s", b)
+	}
+}
+
+func TestSyncObjects(t *testing.T) {
+	dir := t.TempDir()
+	dumpFile := filepath.Join(dir, "site.dmp")
+	outDir := filepath.Join(dir, "repo")
+	dump := strings.Replace(exportDump, "EndController", "Device : Folder\nObject : SAT\nType : InfinityInput\nEndObject\nEndDevice\nEndController", 1)
+	os.WriteFile(dumpFile, []byte(dump), 0o644)
+
+	(&Command{FileName: dumpFile, OutDir: outDir, Objects: true, Sync: true}).Execute()
+	sat := filepath.Join(outDir, "NUSite", "Ctrl1", "Folder", "SAT.json")
+	b, err := os.ReadFile(sat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &entry{}
+	json.Unmarshal(b, e)
+	if e.Type != "InfinityInput" || e.File != "" {
+		t.Errorf("expected the properties of the object got %s", b)
+	}
+
+	os.WriteFile(dumpFile, []byte(exportDump), 0o644)
+	(&Command{FileName: dumpFile, OutDir: outDir, Objects: true, Sync: true}).Execute()
+	if _, err := os.Stat(filepath.Dir(sat)); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected the empty folder to be removed")
+	}
+}
@@ -0,0 +1,117 @@
+package pe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+// changes made to the files by a sync
+const (
+	changeAdded   = "added"
+	changeUpdated = "updated"
+	changeRemoved = "removed"
+)
+
+// change is a file changed by a sync
+type change struct {
+	status string
+	file   string
+}
+
+// relPath returns the file relative to the output directory
+func (s *peHandler) relPath(file string) string {
+	rel, err := filepath.Rel(s.destDir, file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writeFile writes the file and sets the file times to the LastChange of
+// the object. With sync the file is only written if the content changed.
+func (s *peHandler) writeFile(file string, data []byte, obj *dmp.Object) error {
+	rel := s.relPath(file)
+	s.written[rel] = true
+
+	status := changeAdded
+	if s.sync {
+		if old, err := os.ReadFile(file); err == nil {
+			if bytes.Equal(old, data) {
+				return nil
+			}
+			status = changeUpdated
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, os.ModePerm); err != nil {
+		return err
+	}
+	if obj != nil {
+		setModified(file, obj)
+	}
+	if s.sync {
+		s.changes = append(s.changes, &change{status, rel})
+	}
+	return nil
+}
+
+// removeOrphans removes the files of the previous manifest that were not
+// written, and the directories left empty. Only the files listed in the
+// manifest are removed.
+func (s *peHandler) removeOrphans(previous *manifest) {
+	if previous == nil {
+		return
+	}
+	for _, e := range previous.Files {
+		if s.written[e.File] {
+			continue
+		}
+		file := filepath.Join(s.destDir, filepath.FromSlash(e.File))
+		for _, name := range []string{file, file + sidecarExt} {
+			if err := os.Remove(name); err == nil {
+				s.changes = append(s.changes, &change{changeRemoved, s.relPath(name)})
+			}
+		}
+		s.removeEmptyDirs(filepath.Dir(file))
+	}
+}
+
+// removeEmptyDirs removes the directory and its parents while they are
+// empty, up to the output directory.
+func (s *peHandler) removeEmptyDirs(dir string) {
+	for {
+		rel, err := filepath.Rel(s.destDir, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// writeChanges writes the files changed by the sync
+func (s *peHandler) writeChanges(w io.Writer) {
+	slices.SortFunc(s.changes, func(a, b *change) int {
+		return strings.Compare(a.file, b.file)
+	})
+	counts := make(map[string]int)
+	for _, c := range s.changes {
+		counts[c.status]++
+		fmt.Fprintf(w, "%-8s %s\n", c.status, c.file)
+	}
+	fmt.Fprintf(w, "%d added, %d updated, %d removed, %d unchanged\n",
+		counts[changeAdded], counts[changeUpdated], counts[changeRemoved],
+		len(s.written)-counts[changeAdded]-counts[changeUpdated])
+}
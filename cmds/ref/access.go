@@ -0,0 +1,67 @@
+package ref
+
+import (
+	"github.com/tpacheco/dmptool/pe"
+)
+
+// access of the reference by the source
+const (
+	accessRead      = "read"
+	accessWrite     = "write"
+	accessReadWrite = "read/write"
+)
+
+// writeSet returns the index of the tokens written by the code
+func writeSet(tks []pe.Token) map[int]bool {
+	writes := make(map[int]bool)
+	for _, i := range pe.Writes(tks) {
+		writes[i] = true
+	}
+	return writes
+}
+
+// accessOf returns the access of the name at the token index
+func accessOf(writes map[int]bool, i int) string {
+	if writes[i] {
+		return accessWrite
+	}
+	return accessRead
+}
+
+// refAccess returns the combined access of all the uses of a reference
+func refAccess(uses []*occurrence) string {
+	read, write := false, false
+	for _, oc := range uses {
+		if oc.access == accessWrite {
+			write = true
+		} else {
+			read = true
+		}
+	}
+	switch {
+	case read && write:
+		return accessReadWrite
+	case write:
+		return accessWrite
+	default:
+		return accessRead
+	}
+}
+
+// onlyWrites removes the uses that read the references, and the
+// references that are only read.
+func (h *refHandler) onlyWrites() {
+	for r, uses := range h.refs {
+		writes := make([]*occurrence, 0, len(uses))
+		for _, oc := range uses {
+			if oc.access == accessWrite {
+				writes = append(writes, oc)
+			}
+		}
+		if len(writes) == 0 {
+			delete(h.refs, r)
+			continue
+		}
+		h.refs[r] = writes
+	}
+}
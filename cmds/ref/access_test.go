@@ -0,0 +1,57 @@
+package ref
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const accessDump = `Path : NUSite
+BeginController : Ctrl1
+Object : Prog
+Type : InfinityProgram
+ByteCode
+NUSite\Ctrl2\A = NUSite\Ctrl2\B
+If NUSite\Ctrl2\C > 1 Then Turn On NUSite\Ctrl2\D
+Set NUSite\Ctrl2\B = 1
+NUSite\Ctrl2\E = NUSite\Ctrl2\E + 1
+EndByteCode
+EndObject
+EndController
+`
+
+func TestAccess(t *testing.T) {
+	h := &refHandler{
+		index:    dmp.NewIndex(),
+		refs:     make(map[string][]*occurrence),
+		withCode: true,
+	}
+	dmp.Parse(strings.NewReader(accessDump), h)
+
+	tests := []struct {
+		ref      string
+		expected string
+	}{
+		{`NUSite\Ctrl2\A`, accessWrite},
+		{`NUSite\Ctrl2\B`, accessReadWrite},
+		{`NUSite\Ctrl2\C`, accessRead},
+		{`NUSite\Ctrl2\D`, accessWrite},
+		{`NUSite\Ctrl2\E`, accessReadWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := refAccess(h.refs[tt.ref]); got != tt.expected {
+				t.Errorf("expected %s got %s", tt.expected, got)
+			}
+		})
+	}
+
+	h.onlyWrites()
+	if _, ok := h.refs[`NUSite\Ctrl2\C`]; ok {
+		t.Error("expected the read only reference to be removed")
+	}
+	if n := len(h.refs[`NUSite\Ctrl2\B`]); n != 1 {
+		t.Errorf("expected 1 write of B got %d", n)
+	}
+}
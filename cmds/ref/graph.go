@@ -0,0 +1,369 @@
+package ref
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/digraph"
+)
+
+// graph formats
+const (
+	graphDot     = "dot"
+	graphGraphML = "graphml"
+	graphMermaid = "mermaid"
+	graphJSON    = "json"
+)
+
+// collapse levels
+const (
+	collapseController = "controller"
+	collapseDevice     = "device"
+)
+
+var (
+	ErrUnknownGraph    = errors.New("unknown graph format")
+	ErrUnknownCollapse = errors.New("unknown collapse level")
+)
+
+// palette is the node colors, assigned to the types in sorted order
+var palette = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462",
+	"#b3de69", "#fccde5", "#d9d9d9", "#bc80bd", "#ccebc5", "#ffed6f",
+}
+
+// node types for the objects not in the dump and collapsed nodes
+const (
+	typeExternal   = "External"
+	typeMissing    = "Missing"
+	typeController = "Controller"
+	typeDevice     = "Device"
+)
+
+type node struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Color string `json:"color"`
+}
+
+type edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+}
+
+// graph is the references as edges from the sources to the targets
+type graph struct {
+	Nodes []*node `json:"nodes"`
+	Edges []*edge `json:"edges"`
+	nodes map[string]*node
+	edges map[edge]*edge
+}
+
+// checkGraph validates the graph format and collapse level
+func checkGraph(format string, collapse string) error {
+	switch strings.ToLower(format) {
+	case graphDot, graphGraphML, graphMermaid, graphJSON:
+	default:
+		return fmt.Errorf("%w %q, expected one of dot, graphml, mermaid, json", ErrUnknownGraph, format)
+	}
+	switch strings.ToLower(collapse) {
+	case "", collapseController, collapseDevice:
+	default:
+		return fmt.Errorf("%w %q, expected controller or device", ErrUnknownCollapse, collapse)
+	}
+	return nil
+}
+
+// buildGraph builds the graph of the references. Objects are collapsed
+// into their controller or device when collapse is set, and the edges
+// within a collapsed node are dropped.
+func buildGraph(h *refHandler, refs []string, dmpPath string, collapse string) *graph {
+	g := &graph{
+		nodes: make(map[string]*node),
+		edges: make(map[edge]*edge),
+	}
+	collapse = strings.ToLower(collapse)
+	depth := len(strings.FieldsFunc(dmpPath, isSeparator))
+
+	for _, r := range refs {
+		for _, oc := range h.refs[r] {
+			src := g.objectNode(h.index, oc.obj, collapse)
+
+			var dst *node
+			if obj := h.index.Resolve(r, oc.obj.Path); obj != nil {
+				dst = g.objectNode(h.index, obj, collapse)
+			} else {
+				typ := typeExternal
+				if oc.status == statusDangling {
+					typ = typeMissing
+				}
+				dst = g.refNode(trimProperty(r), typ, collapse, depth)
+			}
+
+			if collapse != "" && src == dst {
+				continue
+			}
+			g.addEdge(src.ID, dst.ID, oc.kind)
+		}
+	}
+
+	// assign the colors by type
+	types := make([]string, 0)
+	for _, n := range g.Nodes {
+		if !slices.Contains(types, n.Type) {
+			types = append(types, n.Type)
+		}
+	}
+	slices.Sort(types)
+	for _, n := range g.Nodes {
+		n.Color = palette[slices.Index(types, n.Type)%len(palette)]
+	}
+	return g
+}
+
+func isSeparator(r rune) bool {
+	return r == '\\' || r == '/'
+}
+
+// graphPath returns the path with \ separators used for the node ids
+func graphPath(s string) string {
+	return strings.ReplaceAll(s, "/", `\`)
+}
+
+func (g *graph) node(id string, typ string) *node {
+	key := strings.ToLower(id)
+	if n, ok := g.nodes[key]; ok {
+		return n
+	}
+	label := id
+	if i := strings.LastIndexByte(id, '\\'); i >= 0 {
+		label = id[i+1:]
+	}
+	n := &node{ID: id, Label: label, Type: typ}
+	g.nodes[key] = n
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// objectNode returns the node of an object in the dump
+func (g *graph) objectNode(x *dmp.Index, obj *dmp.Object, collapse string) *node {
+	switch collapse {
+	case collapseDevice:
+		if d := x.Device(obj); d != "" {
+			return g.node(graphPath(d), typeDevice)
+		}
+		fallthrough
+	case collapseController:
+		if c := x.Controller(obj); c != "" {
+			return g.node(graphPath(c), typeController)
+		}
+	}
+	return g.node(graphPath(obj.Path), obj.Type)
+}
+
+// refNode returns the node of a reference not in the dump. When collapsed
+// the controller is assumed to be at the same depth as the dump device,
+// and the device is the folder of the reference.
+func (g *graph) refNode(r string, typ string, collapse string, depth int) *node {
+	r = graphPath(r)
+	parts := strings.Split(r, `\`)
+	switch collapse {
+	case collapseDevice:
+		if len(parts) > depth+1 {
+			return g.node(strings.Join(parts[:len(parts)-1], `\`), typeDevice)
+		}
+		fallthrough
+	case collapseController:
+		if c := controllerOf(r, depth); c != "" {
+			return g.node(c, typeController)
+		}
+	}
+	return g.node(r, typ)
+}
+
+func (g *graph) addEdge(src string, dst string, kind string) {
+	key := edge{Source: src, Target: dst, Kind: kind}
+	if e, ok := g.edges[key]; ok {
+		e.Count++
+		return
+	}
+	e := &edge{Source: src, Target: dst, Kind: kind, Count: 1}
+	g.edges[key] = e
+	g.Edges = append(g.Edges, e)
+}
+
+// writeGraph writes the graph in the format
+func writeGraph(w io.Writer, format string, g *graph) error {
+	switch strings.ToLower(format) {
+	case graphDot:
+		return writeDot(w, g)
+	case graphGraphML:
+		return writeGraphML(w, g)
+	case graphMermaid:
+		return writeMermaid(w, g)
+	case graphJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownGraph, format)
+	}
+}
+
+func writeDot(w io.Writer, g *graph) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph references {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=filled];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(b, "  %s [label=%s, tooltip=%s, fillcolor=%s];\n",
+			digraph.DotQuote(n.ID), digraph.DotQuote(n.Label), digraph.DotQuote(n.Type+": "+n.ID), digraph.DotQuote(n.Color))
+	}
+	for _, e := range g.Edges {
+		label := e.Kind
+		if e.Count > 1 {
+			label = fmt.Sprintf("%s (%d)", e.Kind, e.Count)
+		}
+		fmt.Fprintf(b, "  %s -> %s [label=%s];\n", digraph.DotQuote(e.Source), digraph.DotQuote(e.Target), digraph.DotQuote(label))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidText escapes the text for a mermaid label
+func mermaidText(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+func writeMermaid(w io.Writer, g *graph) error {
+	b := &strings.Builder{}
+	b.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	classes := make(map[string]string)
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i+1)
+		ids[n.ID] = id
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", id, mermaidText(n.Label))
+		if _, ok := classes[n.Type]; !ok {
+			classes[n.Type] = n.Color
+		}
+	}
+	for _, e := range g.Edges {
+		label := e.Kind
+		if e.Count > 1 {
+			label = fmt.Sprintf("%s (%d)", e.Kind, e.Count)
+		}
+		fmt.Fprintf(b, "  %s -->|%s| %s\n", ids[e.Source], mermaidText(label), ids[e.Target])
+	}
+	types := make([]string, 0, len(classes))
+	for t := range classes {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	for i, t := range types {
+		fmt.Fprintf(b, "  classDef t%d fill:%s\n", i, classes[t])
+		members := make([]string, 0)
+		for _, n := range g.Nodes {
+			if n.Type == t {
+				members = append(members, ids[n.ID])
+			}
+		}
+		fmt.Fprintf(b, "  class %s t%d\n", strings.Join(members, ","), i)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type graphmlKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlGraph struct {
+	ID      string        `xml:"id,attr"`
+	Default string        `xml:"edgedefault,attr"`
+	Nodes   []graphmlNode `xml:"node"`
+	Edges   []graphmlEdge `xml:"edge"`
+}
+
+type graphmlDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+func writeGraphML(w io.Writer, g *graph) error {
+	doc := &graphmlDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "color", For: "node", Name: "color", Type: "string"},
+			{ID: "kind", For: "edge", Name: "kind", Type: "string"},
+			{ID: "count", For: "edge", Name: "count", Type: "int"},
+		},
+		Graph: graphmlGraph{
+			ID:      "references",
+			Default: "directed",
+		},
+	}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{
+			ID: n.ID,
+			Data: []graphmlData{
+				{Key: "label", Value: n.Label},
+				{Key: "type", Value: n.Type},
+				{Key: "color", Value: n.Color},
+			},
+		})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphmlData{
+				{Key: "kind", Value: e.Kind},
+				{Key: "count", Value: fmt.Sprint(e.Count)},
+			},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,133 @@
+package ref
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const graphDump = `Path : NUSite
+BeginController : Ctrl1
+Device : AHU1
+Object : Prog
+Type : InfinityProgram
+ByteCode
+Fan = NUSite\Ctrl2\AHU2\Fan
+SAT = NUSite\Ctrl1\OAT
+EndByteCode
+EndObject
+Object : Fan
+Type : InfinityOutput
+EndObject
+Object : SAT
+Type : InfinityNumeric
+EndObject
+EndDevice
+Object : OAT
+Type : InfinityInput
+EndObject
+EndController
+`
+
+func newGraphHandler() (*refHandler, []string) {
+	h := &refHandler{
+		index:    dmp.NewIndex(),
+		refs:     make(map[string][]*occurrence),
+		withCode: true,
+	}
+	dmp.Parse(strings.NewReader(graphDump), h)
+	h.scanLocals()
+	h.classifyAll(`NUSite\Ctrl1`)
+	refs := []string{}
+	for r := range h.refs {
+		refs = append(refs, r)
+	}
+	return h, refs
+}
+
+func TestBuildGraph(t *testing.T) {
+
+	tests := []struct {
+		collapse string
+		nodes    int
+		edges    []string
+	}{
+		{"", 5, []string{
+			`NUSite\Ctrl1\AHU1\Prog>NUSite\Ctrl1\AHU1\Fan`,
+			`NUSite\Ctrl1\AHU1\Prog>NUSite\Ctrl1\AHU1\SAT`,
+			`NUSite\Ctrl1\AHU1\Prog>NUSite\Ctrl1\OAT`,
+			`NUSite\Ctrl1\AHU1\Prog>NUSite\Ctrl2\AHU2\Fan`,
+		}},
+		{"device", 3, []string{
+			`NUSite\Ctrl1\AHU1>NUSite\Ctrl1`,
+			`NUSite\Ctrl1\AHU1>NUSite\Ctrl2\AHU2`,
+		}},
+		{"controller", 2, []string{
+			`NUSite\Ctrl1>NUSite\Ctrl2`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.collapse, func(t *testing.T) {
+			h, refs := newGraphHandler()
+			g := buildGraph(h, refs, `NUSite\Ctrl1`, tt.collapse)
+			if len(g.Nodes) != tt.nodes {
+				t.Errorf("expected %d nodes got %d", tt.nodes, len(g.Nodes))
+			}
+			got := make(map[string]bool)
+			for _, e := range g.Edges {
+				got[strings.ToLower(e.Source+">"+e.Target)] = true
+			}
+			if len(got) != len(tt.edges) {
+				t.Errorf("expected %v got %v", tt.edges, got)
+			}
+			for _, e := range tt.edges {
+				if !got[strings.ToLower(e)] {
+					t.Errorf("expected edge %s", e)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteGraph(t *testing.T) {
+	h, refs := newGraphHandler()
+	g := buildGraph(h, refs, `NUSite\Ctrl1`, collapseController)
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{graphDot, `"NUSite\\Ctrl1" -> "NUSite\\Ctrl2" [label="code"];`},
+		{graphMermaid, `n1 -->|code| n2`},
+		{graphGraphML, `<edge source="NUSite\Ctrl1" target="NUSite\Ctrl2">`},
+		{graphJSON, `"target": "NUSite\\Ctrl2"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := writeGraph(b, tt.format, g); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(b.String(), tt.expected) {
+				t.Errorf("expected %s in\n%s", tt.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestCheckGraph(t *testing.T) {
+	if err := checkGraph("DOT", "Device"); err != nil {
+		t.Error(err)
+	}
+	if err := checkGraph("png", ""); !errors.Is(err, ErrUnknownGraph) {
+		t.Errorf("expected unknown graph got %v", err)
+	}
+	if err := checkGraph("dot", "site"); !errors.Is(err, ErrUnknownCollapse) {
+		t.Errorf("expected unknown collapse got %v", err)
+	}
+}
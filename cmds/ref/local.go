@@ -0,0 +1,54 @@
+package ref
+
+import (
+	"strings"
+
+	"github.com/tpacheco/dmptool/pe"
+)
+
+// scanLocals adds the references made by bare names, like SAT, to the
+// objects in the same folder as the program. The programs are scanned
+// after the dump is parsed so objects after the program are found.
+// Local variables, arguments and line labels declared in the program
+// are not references.
+func (h *refHandler) scanLocals() {
+	for _, do := range h.programs {
+		code := do.Properties["ByteCode"]
+		lines := strings.Split(code, "\n")
+		tks := pe.Scan(code)
+		locals := pe.Locals(tks)
+		writes := writeSet(tks)
+		dir := folder(do.Path)
+
+		for i, tk := range tks {
+			if tk.Kind != pe.TokIdent || strings.ContainsAny(tk.Text, `\/`) {
+				continue
+			}
+			name := trimProperty(tk.Text)
+			if locals[strings.ToLower(name)] {
+				continue
+			}
+			obj := h.index.Lookup(dir + `\` + name)
+			if obj == nil || obj == do {
+				continue
+			}
+			r := strings.ReplaceAll(obj.Path, "/", `\`) + tk.Text[len(name):]
+			h.add(r, &occurrence{
+				obj:    do,
+				kind:   kindCode,
+				line:   tk.Line,
+				text:   strings.TrimSpace(lines[tk.Line-1]),
+				access: accessOf(writes, i),
+			})
+		}
+	}
+}
+
+// folder returns the path of the folder of the object path
+func folder(path string) string {
+	path = strings.ReplaceAll(path, "/", `\`)
+	if i := strings.LastIndexByte(path, '\\'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
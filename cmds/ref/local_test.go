@@ -0,0 +1,66 @@
+package ref
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"golang.org/x/exp/maps"
+)
+
+const localDump = `Path : NUSite
+BeginController : Ctrl1
+Object : Prog
+Type : InfinityProgram
+ByteCode
+Numeric Tmp
+Line Start
+Tmp = SAT + Calc(OAT.Value) 'Fan
+If Tmp > 1 Then Turn On Fan
+Goto Start
+EndByteCode
+EndObject
+Object : SAT
+Type : InfinityInput
+EndObject
+Object : OAT
+Type : InfinityInput
+EndObject
+Object : Tmp
+Type : InfinityNumeric
+EndObject
+Object : Calc
+Type : InfinityFunction
+EndObject
+EndController
+`
+
+func TestScanLocals(t *testing.T) {
+	h := &refHandler{
+		index:    dmp.NewIndex(),
+		refs:     make(map[string][]*occurrence),
+		withCode: true,
+	}
+	dmp.Parse(strings.NewReader(localDump), h)
+	h.scanLocals()
+
+	got := maps.Keys(h.refs)
+	slices.Sort(got)
+	expected := []string{
+		`NUSite\Ctrl1\Calc`,
+		`NUSite\Ctrl1\OAT.Value`,
+		`NUSite\Ctrl1\SAT`,
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+	for i := range got {
+		if !strings.EqualFold(strings.ReplaceAll(got[i], "/", `\`), expected[i]) {
+			t.Errorf("expected %s got %s", expected[i], got[i])
+		}
+	}
+	if oc := h.refs[got[2]][0]; oc.line != 3 {
+		t.Errorf("expected line 3 got %d", oc.line)
+	}
+}
@@ -1,44 +1,51 @@
 package ref
 
 import (
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"os"
-	"path"
 	"slices"
+	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/tpacheco/dmptool/dmp"
-	"github.com/xuri/excelize/v2"
+	"github.com/tpacheco/dmptool/internal/output"
+	"github.com/tpacheco/dmptool/pe"
 	"golang.org/x/exp/maps"
 )
 
-const (
-	csvExt  = ".csv"
-	xlsxExt = ".xlsx"
-)
-
 type refHandler struct {
 	dmp.EmptyHandler
-	refs         map[string][]*dmp.Object
+	index        *dmp.Index
+	refs         map[string][]*occurrence
+	programs     []*dmp.Object
 	withGraphics bool
 	withCode     bool
 	withAlarms   bool
 }
 
-func isValid(r rune) bool {
-	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\\' || r == '_' || r == '.'
+// kinds of reference sources
+const (
+	kindCode     = "code"
+	kindGraphics = "graphic"
+	kindAlarm    = "alarm"
+)
+
+// occurrence is a single use of a reference in a source object
+type occurrence struct {
+	obj  *dmp.Object
+	kind string
+	// line is the line number in the ByteCode or PanelObjectList,
+	// or the alarm link id for alarm links.
+	line   int
+	text   string
+	access string
+	status status
 }
 
-func removeComment(s string) string {
-	i := strings.Index(s, "'")
-	if i < 0 {
-		return s
-	}
-	return s[:i]
+func isValid(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\\' || r == '_' || r == '.'
 }
 
 func parseRefs(s string) []string {
@@ -63,8 +70,66 @@ func parseRefs(s string) []string {
 	return append([]string{s[start:end]}, parseRefs(s[end:])...)
 }
 
+func (h *refHandler) add(r string, oc *occurrence) {
+	h.refs[r] = append(h.refs[r], oc)
+}
+
+// scanLines adds the references found in each line of the text, all
+// references are read.
+func (h *refHandler) scanLines(do *dmp.Object, kind string, text string) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		for _, r := range parseRefs(line) {
+			h.add(r, &occurrence{
+				obj:    do,
+				kind:   kind,
+				line:   i + 1,
+				text:   strings.TrimSpace(line),
+				access: accessRead,
+			})
+		}
+	}
+}
+
+// scanCode adds the path names found in the tokens of the code. Comments
+// and strings are skipped by the lexer.
+func (h *refHandler) scanCode(do *dmp.Object, code string) {
+	lines := strings.Split(code, "\n")
+	tks := pe.Scan(code)
+	writes := writeSet(tks)
+	for i, tk := range tks {
+		if tk.Kind != pe.TokIdent || !strings.Contains(tk.Text, "\\") {
+			continue
+		}
+		text := ""
+		if tk.Line <= len(lines) {
+			text = strings.TrimSpace(lines[tk.Line-1])
+		}
+		h.add(tk.Text, &occurrence{
+			obj:    do,
+			kind:   kindCode,
+			line:   tk.Line,
+			text:   text,
+			access: accessOf(writes, i),
+		})
+	}
+}
+
+// Begin passes the controllers and devices to the index
+func (h *refHandler) Begin(tag string, name string) {
+	h.index.Begin(tag, name)
+}
+
+// End passes the controllers and devices to the index
+func (h *refHandler) End(tag string, name string) {
+	h.index.End(tag, name)
+}
+
 func (h *refHandler) Object(do *dmp.Object) {
 
+	h.index.Add(do)
+
 	typ := do.Type
 
 	switch typ {
@@ -73,13 +138,7 @@ func (h *refHandler) Object(do *dmp.Object) {
 			return
 		}
 		if cdt, ok := do.Properties["PanelObjectList"]; ok {
-			lines := strings.Split(cdt, "\n")
-			for _, line := range lines {
-				refs := parseRefs(line)
-				for _, r := range refs {
-					h.refs[r] = append(h.refs[r], do)
-				}
-			}
+			h.scanLines(do, kindGraphics, cdt)
 			return
 		}
 
@@ -88,14 +147,8 @@ func (h *refHandler) Object(do *dmp.Object) {
 			return
 		}
 		if byteCode, ok := do.Properties["ByteCode"]; ok {
-			lines := strings.Split(byteCode, "\n")
-			for _, line := range lines {
-				line = removeComment(line)
-				refs := parseRefs(line)
-				for _, r := range refs {
-					h.refs[r] = append(h.refs[r], do)
-				}
-			}
+			h.scanCode(do, byteCode)
+			h.programs = append(h.programs, do)
 			return
 		}
 
@@ -109,44 +162,102 @@ func (h *refHandler) Object(do *dmp.Object) {
 				if alarm == nil {
 					continue
 				}
-				r := alarm.Path
-				h.refs[r] = append(h.refs[r], do)
+				state := "Disabled"
+				if alarm.Enabled {
+					state = "Enabled"
+				}
+				h.add(alarm.Path, &occurrence{
+					obj:    do,
+					kind:   kindAlarm,
+					line:   alarm.Id,
+					text:   fmt.Sprintf("%s : %d : %s", alarm.Path, alarm.Id, state),
+					access: accessRead,
+				})
 			}
 			return
 		}
 	}
 }
 
+// ErrFlagConflict is returned for flags that cannot be used together
+var ErrFlagConflict = errors.New("cannot use")
+
 type Command struct {
-	FileName string
-	OutFile  string
-	Bare     bool
-	All      bool
-	Sources  bool
-	ShowType bool
-	Graphics bool
-	Alarms   bool
-	Code     bool
+	FileName  string
+	FileNames []string
+	Site      bool
+	OutFile   string
+	Bare      bool
+	All       bool
+	Sources   bool
+	ShowType  bool
+	Graphics  bool
+	Alarms    bool
+	Code      bool
+	Dangling  bool
+	Target    string
+	Graph     string
+	Collapse  string
+	Writes    bool
+	Stats     bool
+	Top       int
+	Format    string
+	SheetBy   string
 }
 
 func (cmd *Command) Execute() {
 
+	if cmd.Site || cmd.Stats {
+		if err := cmd.checkSiteFlags(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !cmd.Site {
+			cmd.FileNames = []string{cmd.FileName}
+		}
+		cmd.executeSite()
+		return
+	}
+
 	h := &refHandler{
-		refs:         make(map[string][]*dmp.Object),
+		index:        dmp.NewIndex(),
+		refs:         make(map[string][]*occurrence),
 		withGraphics: cmd.Graphics,
 		withCode:     cmd.Code,
 		withAlarms:   cmd.Alarms,
 	}
 
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if cmd.Graph != "" {
+		if err := checkGraph(cmd.Graph, cmd.Collapse); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
 	dmpPath := dmp.ParseFile(cmd.FileName, h)
+	h.scanLocals()
+	if cmd.Writes {
+		h.onlyWrites()
+	}
+
+	h.classifyAll(dmpPath)
+	switch {
+	case cmd.Target != "":
+	case cmd.Dangling:
+		h.onlyStatus(statusDangling)
+	case !cmd.All:
+		h.onlyStatus(statusExternal)
+	}
 
 	refs := maps.Keys(h.refs)
 	slices.Sort(refs)
-
-	if !cmd.All {
-		refs = slices.DeleteFunc(refs, func(s string) bool {
-			return strings.HasPrefix(s, dmpPath)
-		})
+	if cmd.Target != "" {
+		refs = targetRefs(h, cmd.Target, refs)
 	}
 
 	if len(refs) == 0 {
@@ -154,58 +265,101 @@ func (cmd *Command) Execute() {
 		return
 	}
 
-	if cmd.Bare {
-		w := os.Stdout
-		if cmd.OutFile != "" {
-			var err error
-			w, err = os.Create(cmd.OutFile)
-			if err != nil {
-				fmt.Println("could not create file")
-				return
-			}
-			defer func() {
-				w.Sync()
-				w.Close()
-			}()
+	if cmd.Graph != "" {
+		g := buildGraph(h, refs, dmpPath, cmd.Collapse)
+		err := output.Create(cmd.OutFile, func(w io.Writer) error {
+			return writeGraph(w, cmd.Graph, g)
+		})
+		if err != nil {
+			fmt.Println(err)
 		}
+		return
+	}
 
-		for _, v := range refs {
-			fmt.Fprintln(w, v)
+	if cmd.Bare {
+		err := output.Create(cmd.OutFile, func(w io.Writer) error {
+			for _, v := range refs {
+				if _, err := fmt.Fprintln(w, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Println(err)
 		}
 		return
 	}
 
 	table := getTable(cmd, refs, h)
+	if cmd.All {
+		addStatus(table, h)
+	}
+	table.SheetBy = cmd.SheetBy
+
+	title := "external"
+	switch {
+	case cmd.Dangling:
+		title = "dangling"
+	case cmd.All:
+		title = "all"
+	}
 
-	switch strings.ToLower(path.Ext(cmd.OutFile)) {
-	case xlsxExt:
-		writeXlsx(cmd.OutFile, table)
-	case csvExt:
-		writeCSV(cmd.OutFile, table)
-	default:
-		w := os.Stdout
-		if cmd.OutFile != "" {
-			f, err := os.Create(cmd.OutFile)
-			if err != nil {
-				fmt.Println("could not create file")
-				return
+	err = output.Create(cmd.OutFile, func(w io.Writer) error {
+		if format == output.Text {
+			if cmd.Target != "" {
+				writeTargetTitle(w, cmd.Target, dmpPath)
+			} else {
+				writeTitle(w, title, dmpPath)
 			}
-			defer func() {
-				f.Sync()
-				f.Close()
-			}()
-			w = f
 		}
-		writeFile(w, dmpPath, table)
+		return output.Write(w, format, table)
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// writeTitle writes the title for the text output
+func writeTitle(w io.Writer, title string, dmpPath string) {
+	fmt.Fprintf(w, "Device %s references\n\n  Source device: %s\n\n", title, dmpPath)
+}
+
+// checkSiteFlags returns an error for the flags that are not used by the
+// site and stats reports, --dangling is only used by the site report.
+func (cmd *Command) checkSiteFlags() error {
+	mode := "--site"
+	if cmd.Stats {
+		mode = "--stats"
+	}
+	unused := make([]string, 0)
+	if cmd.Graph != "" {
+		unused = append(unused, "--graph")
+	}
+	if cmd.Target != "" {
+		unused = append(unused, "--target")
+	}
+	if cmd.Dangling && cmd.Stats {
+		unused = append(unused, "--dangling")
+	}
+	if cmd.Bare {
+		unused = append(unused, "--bare")
+	}
+	if len(unused) > 0 {
+		return fmt.Errorf("%w %s with %s", ErrFlagConflict, strings.Join(unused, ", "), mode)
 	}
+	return nil
 }
 
-func writeFile(w io.Writer, dmpPath string, table [][]string) {
-	fmt.Fprintf(w, "Device external references\n\n  Source device: %s\n\n", dmpPath)
-	printTable(w, table, false)
+// writeTargetTitle writes the title for the text output of a target lookup
+func writeTargetTitle(w io.Writer, target string, dmpPath string) {
+	fmt.Fprintf(w, "References to %s\n\n  Source device: %s\n\n", target, dmpPath)
 }
 
-func getTable(cmd *Command, refs []string, h *refHandler) [][]string {
+func getTable(cmd *Command, refs []string, h *refHandler) *output.Table {
+	if cmd.Target != "" {
+		return withLines(refs, h)
+	}
 	f := withoutSource
 	if cmd.Sources {
 		f = withSource
@@ -216,144 +370,50 @@ func getTable(cmd *Command, refs []string, h *refHandler) [][]string {
 	return f(refs, h)
 }
 
-func withoutSource(refs []string, h *refHandler) [][]string {
-
-	table := make([][]string, 0, len(h.refs))
-	table = append(table, []string{"External Reference", "Count"})
-
-	for _, v := range refs {
-		table = append(table, []string{v, fmt.Sprintf("%5d", len(h.refs[v]))})
+// addStatus adds the status column after the reference column
+func addStatus(table *output.Table, h *refHandler) {
+	table.Header = slices.Insert(table.Header, 1, "Status")
+	for i, row := range table.Rows {
+		table.Rows[i] = slices.Insert(row, 1, refStatus(h.refs[row[0]]))
 	}
-	return table
 }
 
-func withSourceAndType(refs []string, h *refHandler) [][]string {
-
-	table := make([][]string, 0, len(h.refs))
-	table = append(table, []string{"External Reference", "Source", "Type Name"})
+func withoutSource(refs []string, h *refHandler) *output.Table {
 
-	for _, v := range refs {
-		for _, do := range h.refs[v] {
-			table = append(table, []string{v, do.Path, do.Type})
-		}
+	table := &output.Table{
+		Header: []string{"External Reference", "Access", "Count"},
+		Rows:   make([][]string, 0, len(refs)),
 	}
-	return table
-}
-
-func withSource(refs []string, h *refHandler) [][]string {
-
-	table := make([][]string, 0, len(h.refs))
-	table = append(table, []string{"External Reference", "Source"})
 	for _, v := range refs {
-		for _, do := range h.refs[v] {
-			table = append(table, []string{v, do.Path})
-		}
+		table.Rows = append(table.Rows, []string{v, refAccess(h.refs[v]), strconv.Itoa(len(h.refs[v]))})
 	}
 	return table
 }
 
-func printTable(w io.Writer, table [][]string, elide bool) {
-
-	col_sep := " "
-	col_pad := " "
-
-	widths := lens(table)
-
-	{
-		// print header
-		pad := ""
-		for i, col := range table[0] {
-			fmt.Fprintf(w, "%s%s", pad, col)
-			pad = strings.Repeat(col_pad, widths[i]-len(col)) + col_sep
-		}
-		fmt.Fprintln(w)
-	}
+func withSourceAndType(refs []string, h *refHandler) *output.Table {
 
-	{
-		// print header line
-		pad := ""
-		for _, col := range widths {
-			fmt.Fprintf(w, "%s%s", pad, strings.Repeat("-", col))
-			pad = col_sep
-		}
-		fmt.Fprintln(w)
+	table := &output.Table{
+		Header: []string{"External Reference", "Source", "Type Name", "Access"},
+		Rows:   make([][]string, 0, len(refs)),
 	}
-
-	ref := ""
-	for _, row := range table[1:] {
-		pad := ""
-		for i, col := range row {
-			if elide && i == 0 && ref == col {
-				col = "-"
-			}
-			fmt.Fprintf(w, "%s%s", pad, col)
-			pad = strings.Repeat(col_pad, widths[i]-len(col)) + col_sep
-		}
-		fmt.Fprintln(w)
-		ref = row[0]
-	}
-}
-
-func lens(table [][]string) []int {
-	widths := make([]int, len(table[1]))
-	for _, row := range table {
-		for i, col := range row {
-			n := len(col)
-			if widths[i] < n {
-				widths[i] = n
-			}
+	for _, v := range refs {
+		for _, oc := range h.refs[v] {
+			table.Rows = append(table.Rows, []string{v, oc.obj.Path, oc.obj.Type, oc.access})
 		}
 	}
-	return widths
+	return table
 }
 
-func writeCSV(fileName string, table [][]string) {
-
-	w, err := os.Create(fileName)
-	if err != nil {
-		fmt.Println("could not create file")
-		return
-	}
-	defer func() {
-		w.Sync()
-		w.Close()
-	}()
-
-	csvW := csv.NewWriter(w)
+func withSource(refs []string, h *refHandler) *output.Table {
 
-	header := table[0]
-	if err := csvW.Write(header); err != nil {
-		log.Fatalln("error writing record to csv:", err)
+	table := &output.Table{
+		Header: []string{"External Reference", "Source", "Access"},
+		Rows:   make([][]string, 0, len(refs)),
 	}
-
-	for _, row := range table[1:] {
-		if err := csvW.Write(row); err != nil {
-			log.Fatalln("error writing record to csv:", err)
-		}
-	}
-	csvW.Flush()
-}
-
-func writeXlsx(fName string, table [][]string) {
-
-	ws := lens(table)
-
-	f := excelize.NewFile()
-	defer func() {
-		if err := f.Close(); err != nil {
-			fmt.Println(err)
+	for _, v := range refs {
+		for _, oc := range h.refs[v] {
+			table.Rows = append(table.Rows, []string{v, oc.obj.Path, oc.access})
 		}
-	}()
-
-	for i, col := range ws {
-		f.SetColWidth("Sheet1", fmt.Sprintf("%c", 'A'+i), fmt.Sprintf("%c", 'A'+i), float64(col))
-	}
-
-	for i, row := range table {
-		f.SetSheetRow("Sheet1", fmt.Sprintf("%c%d", 'A', i+1), &row)
-	}
-
-	if err := f.SaveAs(fName); err != nil {
-		fmt.Println(err)
 	}
+	return table
 }
@@ -1,6 +1,12 @@
 package ref
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"golang.org/x/exp/maps"
+)
 
 func TestParseRef(t *testing.T) {
 	s := `	HwPumpStatus = NUSite\EllHall\Hx1HwPumpStatus`
@@ -37,3 +43,68 @@ func TestParseRef2(t *testing.T) {
 		t.Errorf("didn't expect any references. got %s", r[0])
 	}
 }
+
+func TestScanCode(t *testing.T) {
+	code := "Print \"it's \", NUSite\\Ctrl2\\A 'it's NUSite\\Ctrl2\\B\n" +
+		"Msg = \"NUSite\\Ctrl2\\C\"\n" +
+		"NUSite\\Ctrl2\\D = 1"
+	h := &refHandler{refs: make(map[string][]*occurrence)}
+	h.scanCode(&dmp.Object{Name: "Prog"}, code)
+
+	tests := []struct {
+		ref    string
+		line   int
+		access string
+	}{
+		{`NUSite\Ctrl2\A`, 1, accessRead},
+		{`NUSite\Ctrl2\D`, 3, accessWrite},
+	}
+	if len(h.refs) != len(tests) {
+		t.Errorf("expected %d references got %v", len(tests), maps.Keys(h.refs))
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			uses := h.refs[tt.ref]
+			if len(uses) != 1 {
+				t.Fatalf("expected 1 use got %d", len(uses))
+			}
+			if uses[0].line != tt.line {
+				t.Errorf("expected line %d got %d", tt.line, uses[0].line)
+			}
+			if uses[0].access != tt.access {
+				t.Errorf("expected %s got %s", tt.access, uses[0].access)
+			}
+		})
+	}
+}
+
+func TestCheckSiteFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      *Command
+		expected string
+	}{
+		{"stats", &Command{Stats: true}, ""},
+		{"site dangling", &Command{Site: true, Dangling: true}, ""},
+		{"site graph bare", &Command{Site: true, Graph: "dot", Bare: true}, "cannot use --graph, --bare with --site"},
+		{"stats dangling", &Command{Stats: true, Dangling: true, Target: "AHU1"}, "cannot use --target, --dangling with --stats"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cmd.checkSiteFlags()
+			if test.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrFlagConflict) {
+				t.Fatalf("expected flag conflict error got %v", err)
+			}
+			if err.Error() != test.expected {
+				t.Errorf("expected %q got %q", test.expected, err)
+			}
+		})
+	}
+}
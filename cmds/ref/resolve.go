@@ -0,0 +1,92 @@
+package ref
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+// status is the resolution of a reference against the objects of the dump
+type status int
+
+const (
+	// statusExternal is a reference outside of the dump device
+	statusExternal status = iota
+
+	// statusResolved is a reference to an object found in the dump
+	statusResolved
+
+	// statusDangling is a reference within the dump device to an object
+	// that does not exist, a typo or a deleted point.
+	statusDangling
+)
+
+func (s status) String() string {
+	switch s {
+	case statusExternal:
+		return "external"
+	case statusResolved:
+		return "resolved"
+	case statusDangling:
+		return "dangling"
+	default:
+		return "unknown"
+	}
+}
+
+// classify resolves the reference made from the source object. References
+// to objects in the index, by path, alias or relative to the source, are
+// resolved. References not found are external when they are outside of
+// the dump device, and dangling when they are within the dump device or
+// relative to the source.
+func classify(x *dmp.Index, dmpPath string, ref string, from *dmp.Object) status {
+	fromPath := ""
+	if from != nil {
+		fromPath = from.Path
+	}
+	switch {
+	case x.Resolve(ref, fromPath) != nil:
+		return statusResolved
+	case x.IsExternal(ref, fromPath, dmpPath):
+		return statusExternal
+	}
+	return statusDangling
+}
+
+// classifyAll classifies every use of the references from its source, a
+// relative reference can resolve from one folder and not from another.
+func (h *refHandler) classifyAll(dmpPath string) {
+	for r, uses := range h.refs {
+		for _, oc := range uses {
+			oc.status = classify(h.index, dmpPath, r, oc.obj)
+		}
+	}
+}
+
+// onlyStatus removes the uses of the references with another status, and
+// the references left without uses.
+func (h *refHandler) onlyStatus(st status) {
+	for r, uses := range h.refs {
+		uses = slices.DeleteFunc(uses, func(oc *occurrence) bool {
+			return oc.status != st
+		})
+		if len(uses) == 0 {
+			delete(h.refs, r)
+			continue
+		}
+		h.refs[r] = uses
+	}
+}
+
+// refStatus returns the statuses of the uses of a reference, joined with
+// a / when the uses are classified differently.
+func refStatus(uses []*occurrence) string {
+	list := make([]string, 0, 1)
+	for _, st := range []status{statusResolved, statusDangling, statusExternal} {
+		if slices.ContainsFunc(uses, func(oc *occurrence) bool { return oc.status == st }) {
+			list = append(list, st.String())
+		}
+	}
+	return strings.Join(list, "/")
+}
@@ -0,0 +1,64 @@
+package ref
+
+import (
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+func TestClassify(t *testing.T) {
+	x := dmp.NewIndex()
+	x.Add(&dmp.Object{Name: "SAT", Path: `NUSite\Ctrl1\SAT`})
+	x.Add(&dmp.Object{Name: "RAT", Alias: "ReturnAirTemp", Path: `NUSite\Ctrl1\ReturnAirTemp`})
+	x.Add(&dmp.Object{Name: "Fan", Path: `NUSite\Ctrl1\AHU1\Fan`})
+
+	prog := &dmp.Object{Name: "Prog", Path: `NUSite\Ctrl1\AHU1\Prog`}
+
+	tests := []struct {
+		ref      string
+		expected status
+	}{
+		{`NUSite\Ctrl1\SAT`, statusResolved},
+		{`nusite\ctrl1\sat.Value`, statusResolved},
+		{`NUSite\Ctrl1\RAT`, statusResolved},
+		{`AHU1\Fan`, statusResolved},
+		{`NUSite\Ctrl1\SATT`, statusDangling},
+		{`NUSite\Ctrl1\AHU1\Pump`, statusDangling},
+		{`AHU1\Pmp`, statusDangling},
+		{`\NUSite\Ctrl1\SATT`, statusDangling},
+		{`NUSite\Ctrl2\OAT`, statusExternal},
+		{`NUSite\Ctrl10\OAT`, statusExternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got := classify(x, `NUSite\Ctrl1`, tt.ref, prog)
+			if got != tt.expected {
+				t.Errorf("expected %s got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClassifyAll(t *testing.T) {
+	h := &refHandler{index: dmp.NewIndex(), refs: make(map[string][]*occurrence)}
+	h.index.Add(&dmp.Object{Name: "Fan", Path: `NUSite\Ctrl1\AHU1\Sub\Fan`})
+
+	inside := &dmp.Object{Name: "P1", Path: `NUSite\Ctrl1\AHU1\P1`}
+	outside := &dmp.Object{Name: "P2", Path: `NUSite\Ctrl1\P2`}
+	h.add(`Sub\Fan`, &occurrence{obj: inside})
+	h.add(`Sub\Fan`, &occurrence{obj: outside})
+	h.add(`NUSite\Ctrl2\OAT`, &occurrence{obj: inside})
+
+	h.classifyAll(`NUSite\Ctrl1`)
+	if got := refStatus(h.refs[`Sub\Fan`]); got != "resolved/dangling" {
+		t.Errorf("expected resolved/dangling got %s", got)
+	}
+
+	h.onlyStatus(statusDangling)
+	if uses := h.refs[`Sub\Fan`]; len(uses) != 1 || uses[0].obj != outside {
+		t.Errorf("expected the use from %s only", outside.Path)
+	}
+	if _, ok := h.refs[`NUSite\Ctrl2\OAT`]; ok {
+		t.Error("expected the external reference to be removed")
+	}
+}
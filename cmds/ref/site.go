@@ -0,0 +1,233 @@
+package ref
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// site reference statuses
+const (
+	siteSatisfied = "satisfied"
+	siteMissing   = "missing"
+)
+
+// siteDump is a dump file of the site
+type siteDump struct {
+	fileName string
+	path     string
+	h        *refHandler
+}
+
+// siteRef is a reference from one dump to another controller
+type siteRef struct {
+	source string
+	target string
+	ref    string
+	status string
+	uses   []*occurrence
+}
+
+// expandFiles expands the file name patterns, for shells that do not
+// expand wildcards. Names without wildcards are kept as given.
+func expandFiles(patterns []string) ([]string, error) {
+	files := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if !strings.ContainsAny(p, "*?[") {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", p)
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// executeSite loads all the dumps of the site into one index and checks
+// the references each dump makes outside of its own device against the
+// objects of all the dumps.
+func (cmd *Command) executeSite() {
+
+	format, err := output.Lookup(cmd.Format, cmd.OutFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	files, err := expandFiles(cmd.FileNames)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	index, dumps := cmd.loadDumps(files)
+
+	if cmd.Stats {
+		cmd.writeStats(format, index, dumps)
+		return
+	}
+
+	refs := siteRefs(index, dumps)
+	if cmd.Dangling {
+		refs = slices.DeleteFunc(refs, func(r *siteRef) bool {
+			return r.status != siteMissing
+		})
+	}
+
+	if len(refs) == 0 {
+		fmt.Println("No references found.")
+		return
+	}
+
+	table := siteTable(refs, cmd.Sources)
+	table.SheetBy = cmd.SheetBy
+
+	err = output.Create(cmd.OutFile, func(w io.Writer) error {
+		if format == output.Text {
+			writeSiteTitle(w, dumps, refs)
+		}
+		return output.Write(w, format, table)
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// loadDumps parses the dump files into one index
+func (cmd *Command) loadDumps(files []string) (*dmp.Index, []*siteDump) {
+	index := dmp.NewIndex()
+	dumps := make([]*siteDump, 0, len(files))
+	for _, f := range files {
+		h := &refHandler{
+			index:        index,
+			refs:         make(map[string][]*occurrence),
+			withGraphics: cmd.Graphics,
+			withCode:     cmd.Code,
+			withAlarms:   cmd.Alarms,
+		}
+		dmpPath := dmp.ParseFile(f, h)
+		h.scanLocals()
+		if cmd.Writes {
+			h.onlyWrites()
+		}
+		dumps = append(dumps, &siteDump{fileName: f, path: dmpPath, h: h})
+	}
+	return index, dumps
+}
+
+// siteRefs returns the references between the controllers of the site,
+// sorted by the source controller, target controller and reference.
+func siteRefs(index *dmp.Index, dumps []*siteDump) []*siteRef {
+	refs := make([]*siteRef, 0)
+	for _, d := range dumps {
+		source := graphPath(d.path)
+		depth := len(strings.FieldsFunc(d.path, isSeparator))
+
+		for r, uses := range d.h.refs {
+			if dmp.HasPathPrefix(r, d.path) {
+				// references within the dump are resolved or dangling
+				continue
+			}
+			sr := &siteRef{
+				source: source,
+				ref:    r,
+				status: siteMissing,
+				uses:   uses,
+			}
+			if obj := index.Resolve(r, uses[0].obj.Path); obj != nil {
+				sr.status = siteSatisfied
+				sr.target = graphPath(index.Controller(obj))
+			} else {
+				sr.target = controllerOf(r, depth)
+			}
+			if sr.target != "" && strings.EqualFold(sr.target, source) {
+				continue
+			}
+			refs = append(refs, sr)
+		}
+	}
+	slices.SortFunc(refs, func(a, b *siteRef) int {
+		return cmp.Or(
+			strings.Compare(a.source, b.source),
+			strings.Compare(a.target, b.target),
+			strings.Compare(a.ref, b.ref),
+		)
+	})
+	return refs
+}
+
+// controllerOf returns the controller path of a reference not in the
+// site, assuming the controllers are at the same depth as the source.
+func controllerOf(r string, depth int) string {
+	parts := strings.Split(graphPath(r), `\`)
+	if depth > 0 && len(parts) > depth {
+		return strings.Join(parts[:depth], `\`)
+	}
+	return ""
+}
+
+func siteTable(refs []*siteRef, sources bool) *output.Table {
+	table := &output.Table{
+		Header: []string{"Source Controller", "Target Controller", "Reference", "Status", "Access", "Count"},
+		Rows:   make([][]string, 0, len(refs)),
+	}
+	if sources {
+		table.Header[len(table.Header)-1] = "Source"
+	}
+	for _, r := range refs {
+		if !sources {
+			table.Rows = append(table.Rows, []string{r.source, r.target, r.ref, r.status, refAccess(r.uses), strconv.Itoa(len(r.uses))})
+			continue
+		}
+		for _, oc := range r.uses {
+			table.Rows = append(table.Rows, []string{r.source, r.target, r.ref, r.status, oc.access, oc.obj.Path})
+		}
+	}
+	return table
+}
+
+// writeSiteTitle writes the title and the count of references between
+// each pair of controllers for the text output
+func writeSiteTitle(w io.Writer, dumps []*siteDump, refs []*siteRef) {
+	fmt.Fprintf(w, "Site references\n\n")
+	for _, d := range dumps {
+		fmt.Fprintf(w, "  %s: %s\n", d.fileName, d.path)
+	}
+	fmt.Fprintln(w)
+
+	type pair struct{ source, target string }
+	pairs := make([]pair, 0)
+	counts := make(map[pair][2]int)
+	for _, r := range refs {
+		p := pair{r.source, r.target}
+		c, ok := counts[p]
+		if !ok {
+			pairs = append(pairs, p)
+		}
+		if r.status == siteSatisfied {
+			c[0]++
+		} else {
+			c[1]++
+		}
+		counts[p] = c
+	}
+	for _, p := range pairs {
+		c := counts[p]
+		fmt.Fprintf(w, "  %s -> %s: %d satisfied, %d missing\n", p.source, p.target, c[0], c[1])
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,102 @@
+package ref
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tpacheco/dmptool/dmp"
+)
+
+const siteDump1 = `Path : NUSite
+BeginController : Ctrl1
+Object : SAT
+Type : InfinityInput
+EndObject
+Object : Prog
+Type : InfinityProgram
+ByteCode
+NUSite\Ctrl2\Remote = SAT
+EndByteCode
+EndObject
+EndController
+`
+
+const siteDump2 = `Path : NUSite
+BeginController : Ctrl2
+Object : Remote
+Type : InfinityNumeric
+EndObject
+Object : Prog
+Type : InfinityProgram
+ByteCode
+Remote = NUSite\Ctrl1\SAT + NUSite\Ctrl3\Gone
+EndByteCode
+EndObject
+EndController
+`
+
+// newTestSite loads the test dumps into one index
+func newTestSite() (*dmp.Index, []*siteDump) {
+	index := dmp.NewIndex()
+	dumps := make([]*siteDump, 0)
+	for _, s := range []string{siteDump1, siteDump2} {
+		h := &refHandler{
+			index:    index,
+			refs:     make(map[string][]*occurrence),
+			withCode: true,
+		}
+		dmp.Parse(strings.NewReader(s), h)
+		h.scanLocals()
+		// the dump device path is the controller
+		path := filepath.Dir(h.programs[0].Path)
+		dumps = append(dumps, &siteDump{path: path, h: h})
+	}
+	return index, dumps
+}
+
+func TestSiteRefs(t *testing.T) {
+	index, dumps := newTestSite()
+
+	refs := siteRefs(index, dumps)
+
+	expected := []string{
+		`NUSite\Ctrl1>NUSite\Ctrl2 NUSite\Ctrl2\Remote satisfied`,
+		`NUSite\Ctrl2>NUSite\Ctrl1 NUSite\Ctrl1\SAT satisfied`,
+		`NUSite\Ctrl2>NUSite\Ctrl3 NUSite\Ctrl3\Gone missing`,
+	}
+	if len(refs) != len(expected) {
+		for _, r := range refs {
+			t.Log(r.source, r.target, r.ref, r.status)
+		}
+		t.Fatalf("expected %d references got %d", len(expected), len(refs))
+	}
+	for i, r := range refs {
+		got := r.source + ">" + r.target + " " + r.ref + " " + r.status
+		if got != expected[i] {
+			t.Errorf("expected %s got %s", expected[i], got)
+		}
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.dmp", "b.dmp", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := expandFiles([]string{filepath.Join(dir, "*.dmp"), filepath.Join(dir, "a.dmp")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected 2 files got %v", files)
+	}
+
+	if _, err := expandFiles([]string{filepath.Join(dir, "*.xyz")}); err == nil {
+		t.Error("expected an error for a pattern without matches")
+	}
+}
@@ -0,0 +1,275 @@
+package ref
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/digraph"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// defaultTop is the number of points and programs ranked
+const defaultTop = 10
+
+// section is a titled table of the statistics
+type section struct {
+	title string
+	table *output.Table
+}
+
+// writeStats writes the reference statistics. The text and markdown
+// formats have a table for each section, the other formats have one
+// table with a Section column.
+func (cmd *Command) writeStats(format output.Format, index *dmp.Index, dumps []*siteDump) {
+
+	top := cmd.Top
+	if top <= 0 {
+		top = defaultTop
+	}
+
+	refs := siteRefs(index, dumps)
+	sections := []*section{
+		{"Most referenced points", pointStats(dumps, top)},
+		{"Programs by external references", programStats(refs, top)},
+		{"Controller external references", controllerStats(refs)},
+		{"Controller cycles", cycleStats(refs)},
+	}
+
+	err := output.Create(cmd.OutFile, func(w io.Writer) error {
+		switch format {
+		case output.Text, output.Markdown:
+			for _, s := range sections {
+				if err := writeSection(w, format, s); err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			table := mergeSections(sections)
+			table.SheetBy = cmd.SheetBy
+			if table.SheetBy == "" && format == output.XLSX {
+				table.SheetBy = "Section"
+			}
+			return output.Write(w, format, table)
+		}
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func writeSection(w io.Writer, format output.Format, s *section) error {
+	title := s.title + "\n\n"
+	if format == output.Markdown {
+		title = "## " + title
+	}
+	if _, err := io.WriteString(w, title); err != nil {
+		return err
+	}
+	if len(s.table.Rows) == 0 {
+		_, err := io.WriteString(w, "  none\n\n")
+		return err
+	}
+	if err := output.Write(w, format, s.table); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// mergeSections joins the sections into one table with the columns of
+// all the sections and a Section column first.
+func mergeSections(sections []*section) *output.Table {
+	header := []string{"Section"}
+	for _, s := range sections {
+		for _, h := range s.table.Header {
+			if !slices.Contains(header, h) {
+				header = append(header, h)
+			}
+		}
+	}
+	table := &output.Table{Header: header}
+	for _, s := range sections {
+		for _, row := range s.table.Rows {
+			r := make([]string, len(header))
+			r[0] = s.title
+			for i, h := range s.table.Header {
+				r[slices.Index(header, h)] = row[i]
+			}
+			table.Rows = append(table.Rows, r)
+		}
+	}
+	return table
+}
+
+// rankStat is a count of uses of a name
+type rankStat struct {
+	name    string
+	typ     string
+	count   int
+	other   int
+	sources map[*dmp.Object]bool
+}
+
+// pointStats ranks the references by the number of sources using them
+func pointStats(dumps []*siteDump, top int) *output.Table {
+	points := make(map[string]*rankStat)
+	for _, d := range dumps {
+		for r, uses := range d.h.refs {
+			key := strings.ToLower(graphPath(r))
+			p, ok := points[key]
+			if !ok {
+				p = &rankStat{name: graphPath(r), sources: make(map[*dmp.Object]bool)}
+				points[key] = p
+			}
+			for _, oc := range uses {
+				p.sources[oc.obj] = true
+				p.other++
+			}
+		}
+	}
+	ranked := make([]*rankStat, 0, len(points))
+	for _, p := range points {
+		p.count = len(p.sources)
+		ranked = append(ranked, p)
+	}
+	sortRanked(ranked)
+
+	table := &output.Table{Header: []string{"Rank", "Reference", "Sources", "Uses"}}
+	for i, p := range ranked[:min(top, len(ranked))] {
+		table.Rows = append(table.Rows, []string{strconv.Itoa(i + 1), p.name, strconv.Itoa(p.count), strconv.Itoa(p.other)})
+	}
+	return table
+}
+
+// programStats ranks the programs by the number of distinct external
+// references, and then by the number of controllers referenced.
+func programStats(refs []*siteRef, top int) *output.Table {
+	type program struct {
+		stat    *rankStat
+		refs    map[string]bool
+		targets map[string]bool
+	}
+	programs := make(map[*dmp.Object]*program)
+	for _, r := range refs {
+		for _, oc := range r.uses {
+			if oc.kind != kindCode {
+				continue
+			}
+			p, ok := programs[oc.obj]
+			if !ok {
+				p = &program{
+					stat:    &rankStat{name: graphPath(oc.obj.Path), typ: oc.obj.Type},
+					refs:    make(map[string]bool),
+					targets: make(map[string]bool),
+				}
+				programs[oc.obj] = p
+			}
+			p.refs[strings.ToLower(r.ref)] = true
+			p.targets[strings.ToLower(r.target)] = true
+		}
+	}
+	ranked := make([]*rankStat, 0, len(programs))
+	for _, p := range programs {
+		p.stat.count = len(p.refs)
+		p.stat.other = len(p.targets)
+		ranked = append(ranked, p.stat)
+	}
+	sortRanked(ranked)
+
+	table := &output.Table{Header: []string{"Rank", "Program", "Type Name", "External References", "Controllers"}}
+	for i, p := range ranked[:min(top, len(ranked))] {
+		table.Rows = append(table.Rows, []string{strconv.Itoa(i + 1), p.name, p.typ, strconv.Itoa(p.count), strconv.Itoa(p.other)})
+	}
+	return table
+}
+
+func sortRanked(ranked []*rankStat) {
+	slices.SortFunc(ranked, func(a, b *rankStat) int {
+		return cmp.Or(
+			cmp.Compare(b.count, a.count),
+			cmp.Compare(b.other, a.other),
+			strings.Compare(a.name, b.name),
+		)
+	})
+}
+
+// controllerStats counts the distinct external references made by each
+// controller and made to each controller
+func controllerStats(refs []*siteRef) *output.Table {
+	type traffic struct {
+		name     string
+		outbound int
+		inbound  int
+	}
+	controllers := make(map[string]*traffic)
+	get := func(name string) *traffic {
+		key := strings.ToLower(name)
+		c, ok := controllers[key]
+		if !ok {
+			c = &traffic{name: name}
+			controllers[key] = c
+		}
+		return c
+	}
+	for _, r := range refs {
+		get(r.source).outbound++
+		if r.target != "" {
+			get(r.target).inbound++
+		}
+	}
+	list := make([]*traffic, 0, len(controllers))
+	for _, c := range controllers {
+		list = append(list, c)
+	}
+	slices.SortFunc(list, func(a, b *traffic) int {
+		return cmp.Or(
+			cmp.Compare(b.outbound+b.inbound, a.outbound+a.inbound),
+			strings.Compare(a.name, b.name),
+		)
+	})
+
+	table := &output.Table{Header: []string{"Controller", "Outbound", "Inbound"}}
+	for _, c := range list {
+		table.Rows = append(table.Rows, []string{c.name, strconv.Itoa(c.outbound), strconv.Itoa(c.inbound)})
+	}
+	return table
+}
+
+// cycleStats lists the groups of controllers that reference each other
+// in a cycle, the strongly connected parts of the controller graph.
+func cycleStats(refs []*siteRef) *output.Table {
+	edges := make(map[string][]string)
+	names := make(map[string]string)
+	counts := make(map[[2]string]int)
+	for _, r := range refs {
+		if r.target == "" {
+			continue
+		}
+		src, dst := strings.ToLower(r.source), strings.ToLower(r.target)
+		names[src], names[dst] = r.source, r.target
+		if counts[[2]string{src, dst}] == 0 {
+			edges[src] = append(edges[src], dst)
+		}
+		counts[[2]string{src, dst}]++
+	}
+
+	table := &output.Table{Header: []string{"Cycle", "Controllers", "References"}}
+	for i, group := range digraph.Cycles(edges) {
+		n := 0
+		display := make([]string, len(group))
+		for j, a := range group {
+			display[j] = names[a]
+			for _, b := range group {
+				n += counts[[2]string{a, b}]
+			}
+		}
+		table.Rows = append(table.Rows, []string{strconv.Itoa(i + 1), strings.Join(display, ", "), strconv.Itoa(n)})
+	}
+	return table
+}
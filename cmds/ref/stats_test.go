@@ -0,0 +1,49 @@
+package ref
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+func TestStats(t *testing.T) {
+	index, dumps := newTestSite()
+	refs := siteRefs(index, dumps)
+
+	tests := []struct {
+		name     string
+		table    *output.Table
+		expected [][]string
+	}{
+		{"points", pointStats(dumps, 2), [][]string{
+			{"1", `NUSite\Ctrl1\SAT`, "2", "2"},
+			{"2", `NUSite\Ctrl2\Remote`, "2", "2"},
+		}},
+		{"programs", programStats(refs, 10), [][]string{
+			{"1", `NUSite\Ctrl2\Prog`, "InfinityProgram", "2", "2"},
+			{"2", `NUSite\Ctrl1\Prog`, "InfinityProgram", "1", "1"},
+		}},
+		{"controllers", controllerStats(refs), [][]string{
+			{`NUSite\Ctrl2`, "2", "1"},
+			{`NUSite\Ctrl1`, "1", "1"},
+			{`NUSite\Ctrl3`, "0", "1"},
+		}},
+		{"cycles", cycleStats(refs), [][]string{
+			{"1", `NUSite\Ctrl1, NUSite\Ctrl2`, "2"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.table.Rows) != len(tt.expected) {
+				t.Fatalf("expected %v got %v", tt.expected, tt.table.Rows)
+			}
+			for i := range tt.expected {
+				if !slices.Equal(tt.table.Rows[i], tt.expected[i]) {
+					t.Errorf("expected %v got %v", tt.expected[i], tt.table.Rows[i])
+				}
+			}
+		})
+	}
+}
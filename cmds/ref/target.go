@@ -0,0 +1,98 @@
+package ref
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/tpacheco/dmptool/internal/output"
+)
+
+// matchTarget tests if the path matches the target pattern. The pattern
+// can use * to match any run of characters, including the path separator,
+// and ? to match a single character. Paths are compared without case and
+// with either path separator.
+func matchTarget(pattern string, path string) bool {
+	return matchGlob(dmp.NormalizePath(pattern), dmp.NormalizePath(path))
+}
+
+func matchGlob(p string, s string) bool {
+	for len(p) > 0 {
+		switch p[0] {
+		case '*':
+			for len(p) > 0 && p[0] == '*' {
+				p = p[1:]
+			}
+			if p == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(p, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != p[0] {
+				return false
+			}
+		}
+		p, s = p[1:], s[1:]
+	}
+	return s == ""
+}
+
+// targetRefs returns the references to the target. A reference matches
+// when the reference, or the path of the object it resolves to, matches
+// the target pattern. Property references like SAT.Value match SAT.
+func targetRefs(h *refHandler, target string, refs []string) []string {
+	return slices.DeleteFunc(slices.Clone(refs), func(r string) bool {
+		if matchTarget(target, r) || matchTarget(target, trimProperty(r)) {
+			return false
+		}
+		for _, oc := range h.refs[r] {
+			if obj := h.index.Resolve(r, oc.obj.Path); obj != nil && matchTarget(target, obj.Path) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// trimProperty removes a property from the end of the reference
+func trimProperty(r string) string {
+	i := strings.LastIndexByte(r, '.')
+	if i > strings.LastIndexAny(r, `\/`) {
+		return r[:i]
+	}
+	return r
+}
+
+// withLines returns a row for each use of the references with the line
+// number and the text of the line.
+func withLines(refs []string, h *refHandler) *output.Table {
+
+	table := &output.Table{
+		Header: []string{"Reference", "Source", "Type Name", "Kind", "Access", "Line", "Text"},
+		Rows:   make([][]string, 0, len(refs)),
+	}
+	for _, v := range refs {
+		for _, oc := range h.refs[v] {
+			table.Rows = append(table.Rows, []string{
+				v,
+				oc.obj.Path,
+				oc.obj.Type,
+				oc.kind,
+				oc.access,
+				strconv.Itoa(oc.line),
+				oc.text,
+			})
+		}
+	}
+	return table
+}
@@ -0,0 +1,47 @@
+package ref
+
+import "testing"
+
+func TestMatchTarget(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{`NUSite\Bldg\AHU1\SAT`, `NUSite\Bldg\AHU1\SAT`, true},
+		{`nusite\bldg\ahu1\sat`, `NUSite\Bldg\AHU1\SAT`, true},
+		{`NUSite\Bldg\AHU1\SAT`, `NUSite/Bldg/AHU1/SAT`, true},
+		{`NUSite\Bldg\AHU1\SAT`, `NUSite\Bldg\AHU1\SATX`, false},
+		{`NUSite\Bldg\*\SAT`, `NUSite\Bldg\AHU1\SAT`, true},
+		{`*\SAT`, `NUSite\Bldg\AHU1\SAT`, true},
+		{`*SAT*`, `NUSite\Bldg\AHU1\SATSpt`, true},
+		{`NUSite\Bldg\AHU?\SAT`, `NUSite\Bldg\AHU2\SAT`, true},
+		{`NUSite\Bldg\AHU?\SAT`, `NUSite\Bldg\AHU10\SAT`, false},
+		{`NUSite\Bldg\AHU1\*`, `NUSite\Bldg\AHU2\SAT`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := matchTarget(tt.pattern, tt.path); got != tt.expected {
+				t.Errorf("expected %v got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTrimProperty(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected string
+	}{
+		{`NUSite\AHU1\SAT.Value`, `NUSite\AHU1\SAT`},
+		{`NUSite\AHU1\SAT`, `NUSite\AHU1\SAT`},
+		{`NUSite\AHU.1\SAT`, `NUSite\AHU.1\SAT`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := trimProperty(tt.ref); got != tt.expected {
+				t.Errorf("expected %s got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tpacheco/dmptool/cmds/list"
+	"github.com/tpacheco/dmptool/internal/config"
+)
+
+// configFile is the configuration file given with the --config flag
+var configFile string
+
+// loadConfig loads the configuration file given with the --config flag
+// or searches for the default configuration file.
+func loadConfig() (*config.Config, error) {
+	if configFile != "" {
+		return config.LoadFile(configFile)
+	}
+	return config.Load()
+}
+
+// applyListConfig applies the named query and output preset to the list
+// command. Flags set on the command line take priority over the values
+// from the configuration. A configuration file that cannot be loaded is
+// only an error when a query or preset is asked for, or the file is given
+// with --config, otherwise the list runs without it.
+func applyListConfig(cc *cobra.Command, cmd *list.Command, query string, preset string) error {
+
+	cfg, err := loadConfig()
+	if err != nil {
+		if query != "" || preset != "" || configFile != "" {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "warning: configuration not used:", err)
+		return nil
+	}
+
+	q := &config.Query{}
+	if query != "" {
+		if q, err = cfg.Query(query); err != nil {
+			return err
+		}
+	}
+
+	// the query preset is applied first so the query and then
+	// the preset from the command line can override it
+	presets := make([]*config.Preset, 0, 2)
+	for _, name := range []string{q.Preset, preset} {
+		if name == "" {
+			continue
+		}
+		p, err := cfg.Preset(name)
+		if err != nil {
+			return err
+		}
+		presets = append(presets, p)
+	}
+
+	flags := cc.Flags()
+	// use the configured value if there is one and the flag is not set
+	use := func(flag string, ok bool) bool {
+		return ok && !flags.Changed(flag)
+	}
+
+	if use("types", len(q.Types) > 0) {
+		cmd.Types = q.Types
+	}
+	if use("names", len(q.Names) > 0) {
+		cmd.Names = q.Names
+	}
+	if use("devices", len(q.Devices) > 0) {
+		cmd.Devices = q.Devices
+	}
+	if use("where", q.Where != "") {
+		cmd.Filter = q.Where
+	}
+	if use("sort", len(q.Sort) > 0) {
+		cmd.Ordering = q.Sort
+	}
+
+	if use("fields", len(q.Fields) > 0) {
+		cmd.Fields = q.Fields
+	}
+	cmd.Defaults = cfg.TypeFields(cmd.Types)
+
+	headers := []map[string]string{cfg.Headers}
+	output, format := "", ""
+	if q.Preset != "" {
+		headers = append(headers, presets[0].Headers)
+		output, format = presets[0].Output, presets[0].Format
+		presets = presets[1:]
+	}
+	headers = append(headers, q.Headers)
+	if q.Output != "" {
+		output = q.Output
+	}
+	for _, p := range presets {
+		headers = append(headers, p.Headers)
+		if p.Output != "" {
+			output = p.Output
+		}
+		if p.Format != "" {
+			format = p.Format
+		}
+	}
+
+	if use("output", output != "") {
+		cmd.OutFile = output
+	}
+	if use("format", format != "") {
+		cmd.Format = format
+	}
+	cmd.Headers = config.ColumnHeaders(headers...)
+
+	return nil
+}
@@ -2,12 +2,26 @@
 package dmp
 
 import (
+	"slices"
 	"time"
 )
 
 const timeLayout = "1/2/2006 3:04:05 PM"
 
+// CodeTypes are the types of the objects with Plain English code
+var CodeTypes = []string{"Program", "InfinityProgram", "InfinityFunction"}
+
+// IsCode tests if the objects of the type have Plain English code
+func IsCode(typeName string) bool {
+	return slices.Contains(CodeTypes, typeName)
+}
+
 // ParseTime parses a dmpfile timestamp string and returns the time value it represents.
 func ParseTime(value string) (time.Time, error) {
 	return time.ParseInLocation(timeLayout, value, time.Local)
 }
+
+// FormatTime formats the time as a dmpfile timestamp string.
+func FormatTime(t time.Time) string {
+	return t.In(time.Local).Format(timeLayout)
+}
@@ -0,0 +1,231 @@
+package dmp
+
+import (
+	"strings"
+)
+
+// Index is a Handler that collects the objects of a dump file and looks
+// up the objects by path.
+//
+// Paths are compared without case and with either path separator. An
+// object with an alias can be found by both the alias and the name.
+type Index struct {
+	EmptyHandler
+	root    string
+	objects []*Object
+	paths   map[string]*Object
+
+	// scopes is the stack of the open controllers, devices and
+	// containers and the scope is the place of each object.
+	scopes []string
+	scope  map[*Object]*scope
+}
+
+// scope is the controller and device of an object
+type scope struct {
+	controller string
+	device     string
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		paths: make(map[string]*Object),
+		scope: make(map[*Object]*scope),
+	}
+}
+
+// NormalizePath returns the path in the form used for comparing paths.
+func NormalizePath(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "/", `\`))
+}
+
+// HasPathPrefix tests if the path is the prefix path or is a
+// descendant of the prefix path.
+func HasPathPrefix(s string, prefix string) bool {
+	s, prefix = NormalizePath(s), NormalizePath(prefix)
+	if prefix == "" {
+		return false
+	}
+	return s == prefix || strings.HasPrefix(s, strings.TrimSuffix(prefix, `\`)+`\`)
+}
+
+// Path records the root path of the dump file
+func (x *Index) Path(s string) {
+	if x.root == "" {
+		x.root = s
+	}
+}
+
+// Begin tracks the controllers, devices and containers the
+// objects are in.
+func (x *Index) Begin(tag string, name string) {
+	switch tag {
+	case tag_dictionary, tag_controller_begin, tag_infinet_ctlr:
+		x.scopes = append(x.scopes, tag_controller)
+	case tag_device:
+		x.scopes = append(x.scopes, tag_device)
+	case tag_container_begin:
+		x.scopes = append(x.scopes, tag_container)
+	}
+}
+
+// End closes the controller, device or container
+func (x *Index) End(tag string, name string) {
+	switch tag {
+	case tag_dictionary, tag_controller, tag_infinet_ctlr, tag_device, tag_container:
+		if len(x.scopes) > 0 {
+			x.scopes = x.scopes[:len(x.scopes)-1]
+		}
+	}
+}
+
+// Object adds the object to the index
+func (x *Index) Object(obj *Object) {
+	x.Add(obj)
+}
+
+// Add adds the object to the index
+func (x *Index) Add(obj *Object) {
+	x.objects = append(x.objects, obj)
+	x.addScope(obj)
+	key := NormalizePath(obj.Path)
+	x.paths[key] = obj
+	if obj.Alias != "" && obj.Alias != obj.Name {
+		// also index the name the alias replaced
+		x.paths[NormalizePath(parentPath(key)+`\`+obj.Name)] = obj
+	}
+}
+
+// addScope records the controller and device of the object from the
+// open scopes. The innermost scope is the folder of the object and each
+// outer scope is a folder up the path.
+func (x *Index) addScope(obj *Object) {
+	if len(x.scopes) == 0 {
+		return
+	}
+	sc := &scope{}
+	dir := obj.Path
+	for i := len(x.scopes) - 1; i >= 0; i-- {
+		dir = parentPath(dir)
+		switch x.scopes[i] {
+		case tag_controller:
+			if sc.controller == "" {
+				sc.controller = dir
+			}
+		case tag_device:
+			if sc.device == "" {
+				sc.device = dir
+			}
+		}
+	}
+	x.scope[obj] = sc
+}
+
+// parentPath returns the path of the parent folder
+func parentPath(s string) string {
+	i := strings.LastIndexAny(s, `\/`)
+	if i < 0 {
+		return ""
+	}
+	return s[:i]
+}
+
+// Controller returns the path of the controller of the object, or an
+// empty string if the object is not in a controller.
+func (x *Index) Controller(obj *Object) string {
+	if sc, ok := x.scope[obj]; ok {
+		return sc.controller
+	}
+	return ""
+}
+
+// Device returns the path of the device of the object, or an empty
+// string if the object is not in a device.
+func (x *Index) Device(obj *Object) string {
+	if sc, ok := x.scope[obj]; ok {
+		return sc.device
+	}
+	return ""
+}
+
+// Root returns the root path of the dump file
+func (x *Index) Root() string {
+	return x.root
+}
+
+// IsExternal tests if the reference made from the object at the from path
+// is to an object outside of the dump device at devPath. References that
+// resolve to an object of the index are not external. References not found
+// that start at the site root are external when they are outside of the
+// dump device, and the other references not found are relative to the
+// source, so they are not external either.
+func (x *Index) IsExternal(ref string, from string, devPath string) bool {
+	if x.Resolve(ref, from) != nil || HasPathPrefix(ref, devPath) {
+		return false
+	}
+	site := x.siteRoot(devPath)
+	return site == "" || HasPathPrefix(ref, site)
+}
+
+// siteRoot returns the root folder of the site, the first folder of the
+// root path of the dump or of the dump device path.
+func (x *Index) siteRoot(devPath string) string {
+	root := x.root
+	if root == "" {
+		root = devPath
+	}
+	root = strings.TrimLeft(strings.ReplaceAll(root, "/", `\`), `\`)
+	if i := strings.IndexByte(root, '\\'); i >= 0 {
+		root = root[:i]
+	}
+	return root
+}
+
+// Objects returns the objects in the order they were added
+func (x *Index) Objects() []*Object {
+	return x.objects
+}
+
+// Len returns the number of objects in the index
+func (x *Index) Len() int {
+	return len(x.objects)
+}
+
+// Lookup returns the object with the path. If the path is not found and
+// ends with a property, as in Point.Value, the path without the property
+// is looked up.
+func (x *Index) Lookup(path string) *Object {
+	key := NormalizePath(path)
+	if obj, ok := x.paths[key]; ok {
+		return obj
+	}
+	i := strings.LastIndexByte(key, '.')
+	if i > strings.LastIndexByte(key, '\\') {
+		if obj, ok := x.paths[key[:i]]; ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Resolve returns the object for a reference made from the object at
+// the from path. The reference is looked up as a full path, and then
+// relative to the folder of the from object and each of its parents.
+func (x *Index) Resolve(ref string, from string) *Object {
+	if obj := x.Lookup(ref); obj != nil {
+		return obj
+	}
+	ref = strings.TrimPrefix(strings.ReplaceAll(ref, "/", `\`), `.\`)
+	dir := NormalizePath(from)
+	for {
+		i := strings.LastIndexByte(dir, '\\')
+		if i < 0 {
+			return nil
+		}
+		dir = dir[:i]
+		if obj := x.Lookup(dir + `\` + ref); obj != nil {
+			return obj
+		}
+	}
+}
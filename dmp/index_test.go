@@ -0,0 +1,141 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+)
+
+const indexDump = `Path : NUSite
+BeginController : Ctrl1
+Object : SAT
+Type : InfinityInput
+EndObject
+Object : RAT
+Alias : ReturnAirTemp
+Type : InfinityInput
+EndObject
+Device : AHU1
+Object : Fan
+Type : InfinityOutput
+EndObject
+EndDevice
+EndController
+`
+
+func newTestIndex(t *testing.T) *Index {
+	x := NewIndex()
+	Parse(strings.NewReader(indexDump), x)
+	if x.Len() != 3 {
+		t.Fatalf("expected 3 objects got %d", x.Len())
+	}
+	return x
+}
+
+func TestIndexLookup(t *testing.T) {
+	x := newTestIndex(t)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{`NUSite\Ctrl1\SAT`, "SAT"},
+		{`nusite\ctrl1\sat`, "SAT"},
+		{`NUSite/Ctrl1/SAT`, "SAT"},
+		{`NUSite\Ctrl1\SAT.Value`, "SAT"},
+		{`NUSite\Ctrl1\ReturnAirTemp`, "RAT"},
+		{`NUSite\Ctrl1\RAT`, "RAT"},
+		{`NUSite\Ctrl1\AHU1\Fan`, "Fan"},
+		{`NUSite\Ctrl1\OAT`, ""},
+		{`NUSite\Ctrl1`, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			obj := x.Lookup(test.path)
+			name := ""
+			if obj != nil {
+				name = obj.Name
+			}
+			if name != test.expected {
+				t.Errorf("expected %q got %q", test.expected, name)
+			}
+		})
+	}
+}
+
+func TestIndexResolve(t *testing.T) {
+	x := newTestIndex(t)
+
+	tests := []struct {
+		ref      string
+		from     string
+		expected string
+	}{
+		{`NUSite\Ctrl1\SAT`, `NUSite\Ctrl1\AHU1\Fan`, "SAT"},
+		{`SAT`, `NUSite\Ctrl1\AHU1\Fan`, "SAT"},
+		{`AHU1\Fan`, `NUSite\Ctrl1\SAT`, "Fan"},
+		{`.\Fan`, `NUSite\Ctrl1\AHU1\Prog`, "Fan"},
+		{`Ctrl1\ReturnAirTemp`, `NUSite\Ctrl1\SAT`, "RAT"},
+		{`Ctrl2\SAT`, `NUSite\Ctrl1\SAT`, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ref, func(t *testing.T) {
+			obj := x.Resolve(test.ref, test.from)
+			name := ""
+			if obj != nil {
+				name = obj.Name
+			}
+			if name != test.expected {
+				t.Errorf("expected %q got %q", test.expected, name)
+			}
+		})
+	}
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	tests := []struct {
+		path     string
+		prefix   string
+		expected bool
+	}{
+		{`NUSite\Ctrl1\SAT`, `NUSite\Ctrl1`, true},
+		{`nusite\ctrl1\SAT`, `NUSite/Ctrl1`, true},
+		{`NUSite\Ctrl1`, `NUSite\Ctrl1`, true},
+		{`NUSite\Ctrl10\SAT`, `NUSite\Ctrl1`, false},
+		{`NUSite\Ctrl1\SAT`, ``, false},
+	}
+	for _, test := range tests {
+		if result := HasPathPrefix(test.path, test.prefix); result != test.expected {
+			t.Errorf("%s %s: expected %v got %v", test.path, test.prefix, test.expected, result)
+		}
+	}
+}
+
+func TestIndexScope(t *testing.T) {
+	x := newTestIndex(t)
+
+	tests := []struct {
+		path       string
+		controller string
+		device     string
+	}{
+		{`NUSite\Ctrl1\SAT`, `NUSite\Ctrl1`, ""},
+		{`NUSite\Ctrl1\AHU1\Fan`, `NUSite\Ctrl1`, `NUSite\Ctrl1\AHU1`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			obj := x.Lookup(test.path)
+			if obj == nil {
+				t.Fatalf("expected object %s", test.path)
+			}
+			if got := x.Controller(obj); NormalizePath(got) != NormalizePath(test.controller) {
+				t.Errorf("expected controller %q got %q", test.controller, got)
+			}
+			if got := x.Device(obj); NormalizePath(got) != NormalizePath(test.device) {
+				t.Errorf("expected device %q got %q", test.device, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package dmp
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// rewriter collects the objects as the dump is read by Rewrite
+type rewriter struct {
+	EmptyHandler
+	begin bool
+	obj   *Object
+}
+
+func (h *rewriter) Begin(tag string, name string) {
+	if tag == tag_object {
+		h.begin = true
+	}
+}
+
+func (h *rewriter) Object(obj *Object) {
+	h.obj = obj
+}
+
+// lineEnd returns the line ending of the raw line
+func lineEnd(raw string) string {
+	switch {
+	case strings.HasSuffix(raw, "\r\n"):
+		return "\r\n"
+	case strings.HasSuffix(raw, "\n"):
+		return "\n"
+	}
+	return ""
+}
+
+// lineText returns the raw line without the line ending
+func lineText(raw string) string {
+	return trimR(strings.TrimSuffix(raw, "\n"))
+}
+
+// Rewrite copies the dump from r to w. The edit function is called with
+// each object and returns the properties to change, or nil to keep the
+// object as it is. The ByteCode and the single line properties of the
+// object can be changed, other properties are ignored. All the other
+// lines are copied as they are, with their line endings.
+func Rewrite(r io.Reader, w io.Writer, edit func(obj *Object) map[string]string) error {
+
+	h := &rewriter{}
+	var p parser = newParser(h)
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+
+	// the lines of the current object are kept until the object ends
+	var lines []string
+	inObject := false
+	n := 0
+	for {
+		raw, err := br.ReadString('\n')
+		if len(raw) > 0 {
+			h.begin, h.obj = false, nil
+			p = p.parse(&token{value: lineText(raw), line: n})
+			n++
+			if h.begin {
+				inObject = true
+			}
+			if inObject {
+				lines = append(lines, raw)
+			} else {
+				bw.WriteString(raw)
+			}
+			if h.obj != nil {
+				writeObject(bw, lines, edit(h.obj))
+				lines, inObject = nil, false
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// an object without the end is copied
+	for _, raw := range lines {
+		bw.WriteString(raw)
+	}
+	return bw.Flush()
+}
+
+// writeObject writes the lines of the object with the changed properties
+func writeObject(w *bufio.Writer, lines []string, changes map[string]string) {
+	// end is the end tag of the block being copied
+	end := ""
+	for i := 0; i < len(lines); i++ {
+		raw := lines[i]
+		text := lineText(raw)
+		if end != "" {
+			if strings.Trim(text, " ") == end {
+				end = ""
+			}
+			w.WriteString(raw)
+			continue
+		}
+
+		k, _, _ := split(text)
+		switch k {
+
+		case prop_bytecode:
+			w.WriteString(raw)
+			j := i + 1
+			for j < len(lines) && strings.TrimSpace(lineText(lines[j])) != prop_bytecode_end {
+				j++
+			}
+			if code, ok := changes[prop_bytecode]; ok {
+				eol := lineEnd(raw)
+				for _, line := range strings.Split(code, new_line) {
+					w.WriteString(line + eol)
+				}
+			} else {
+				for _, line := range lines[i+1 : j] {
+					w.WriteString(line)
+				}
+			}
+			i = j - 1
+			continue
+
+		case "{":
+			end = "EndOfCDT"
+
+		case "PanelObjectList":
+			end = "}"
+
+		case prop_array, prop_members, prop_alarm_links:
+			end = "End" + k
+
+		default:
+			v, ok := changes[k]
+			c := strings.IndexByte(raw, ':')
+			if ok && c >= 0 {
+				w.WriteString(raw[:c+1] + " " + v + lineEnd(raw))
+				continue
+			}
+		}
+		w.WriteString(raw)
+	}
+}
@@ -0,0 +1,63 @@
+package dmp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const writerDump = "Path : NUSite\r\n" +
+	"BeginController : Ctrl1\r\n" +
+	"Object : Prog\r\n" +
+	"Type : InfinityProgram\r\n" +
+	"LastChange : 1/4/2024 3:04:05 PM\r\n" +
+	"ByteCode\r\n" +
+	"x=1\r\n" +
+	"  y = 2\r\n" +
+	"EndByteCode\r\n" +
+	"AlarmLinks\r\n" +
+	"LastChange : 1 : Enabled\r\n" +
+	"EndAlarmLinks\r\n" +
+	"EndObject\r\n" +
+	"Object : SAT\r\n" +
+	"Type : InfinityInput\r\n" +
+	"EndObject\r\n" +
+	"EndController\r\n"
+
+func TestRewrite(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		changes  map[string]string
+		expected string
+	}{
+		{"unchanged", nil, writerDump},
+		{"code", map[string]string{"ByteCode": "x = 1\ny = 2\nz = 3"}, strings.Replace(writerDump,
+			"x=1\r\n  y = 2\r\n", "x = 1\r\ny = 2\r\nz = 3\r\n", 1)},
+		{"property", map[string]string{"LastChange": "2/5/2024 4:05:06 PM"}, strings.Replace(writerDump,
+			"LastChange : 1/4/2024 3:04:05 PM", "LastChange : 2/5/2024 4:05:06 PM", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := make([]string, 0)
+			buf := &bytes.Buffer{}
+			err := Rewrite(strings.NewReader(writerDump), buf, func(obj *Object) map[string]string {
+				objects = append(objects, obj.Name)
+				if obj.Name == "Prog" {
+					return tt.changes
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("expected\n%q\ngot\n%q", tt.expected, got)
+			}
+			if strings.Join(objects, ",") != "Prog,SAT" {
+				t.Errorf("expected the objects Prog,SAT got %v", objects)
+			}
+		})
+	}
+}
@@ -2,14 +2,26 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	_ "embed"
 
 	"github.com/spf13/cobra"
+	"github.com/tpacheco/dmptool/cmds/alarms"
+	"github.com/tpacheco/dmptool/cmds/calls"
+	"github.com/tpacheco/dmptool/cmds/fields"
+	"github.com/tpacheco/dmptool/cmds/format"
+	"github.com/tpacheco/dmptool/cmds/graphics"
+	"github.com/tpacheco/dmptool/cmds/grep"
+	"github.com/tpacheco/dmptool/cmds/lint"
 	"github.com/tpacheco/dmptool/cmds/list"
+	"github.com/tpacheco/dmptool/cmds/metrics"
 	"github.com/tpacheco/dmptool/cmds/pe"
 	"github.com/tpacheco/dmptool/cmds/ref"
 	"github.com/tpacheco/dmptool/cmds/tree"
+	"github.com/tpacheco/dmptool/internal/config"
+	"github.com/tpacheco/dmptool/internal/output"
 )
 
 var (
@@ -51,6 +63,18 @@ The --prefix flag set a prefix for the type folders. The default prefix is "__".
 			__Program
 			__InfinityProgram
 			__InfinityFunction
+
+The modified time of each file is set to the LastChange of the program.
+
+The --sidecar flag writes a .pe.json file next to each program file with the
+type, path and all the other properties of the program. The --manifest flag
+writes a manifest.json to the output directory listing every file with the
+program path, the properties and the sha256 checksum of the code.
+
+The --sync flag updates a previous export: only the files that changed are
+written, and the files listed in the previous manifest.json that are no longer
+exported are removed. The manifest is always written with --sync. The files
+added, updated and removed are listed.
 `,
 		Aliases: []string{"script", "code", "programs"},
 		Args:    cobra.MinimumNArgs(1),
@@ -68,6 +92,49 @@ The --prefix flag set a prefix for the type folders. The default prefix is "__".
 	cf.BoolVarP(&pe.Flatten, "flatten", "f", false, "flatten the file path to a single name")
 	cf.StringVar(&pe.FlattenSep, "separator", "~", "separator used when flattening file paths")
 	cf.StringVar(&pe.TypePrefix, "prefix", "__", "prefix used when including file types")
+	cf.BoolVar(&pe.Sidecar, "sidecar", false, "write a .pe.json file with the properties of each program")
+	cf.BoolVar(&pe.Manifest, "manifest", false, "write a manifest.json listing the files with their checksums")
+	cf.BoolVar(&pe.Sync, "sync", false, "write only the changed files and remove the files no longer exported")
+
+	cc.AddCommand(newCmdPEImport())
+	return cc
+}
+
+func newCmdPEImport() *cobra.Command {
+	cmdImport := &pe.ImportCommand{}
+
+	cc := &cobra.Command{
+		Use:   "import <dump file> <directory> -o <output dump file>",
+		Short: "imports the edited PE program files back into a dump file",
+		Long: `This command will replace the code of the programs in the dump file with
+the PE program files in the directory, and write the new dump file given
+with the --output flag. The LastChange of each updated program is set to the
+modified time of the file.
+
+The files are matched to the programs with the same layout as the pe command,
+so the --type, --flatten, --separator and --prefix flags must be the same as
+when the files were extracted.
+
+The report lists the programs updated, the files added without a program in
+the dump, the programs removed without a file, and the programs unmatched when
+more than one program has the same file name. Only the code of existing
+programs is imported.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdImport.FileName = args[0]
+			cmdImport.Dir = args[1]
+			cmdImport.Execute()
+		},
+	}
+
+	cf := cc.Flags()
+	cf.StringVarP(&cmdImport.OutFile, "output", "o", "", "output dump file to write to")
+	cf.BoolVarP(&cmdImport.TypeFolder, "type", "t", false, "the typename is a folder for the files")
+	cf.BoolVarP(&cmdImport.Flatten, "flatten", "f", false, "the file path is flattened to a single name")
+	cf.StringVar(&cmdImport.FlattenSep, "separator", "~", "separator used when flattening file paths")
+	cf.StringVar(&cmdImport.TypePrefix, "prefix", "__", "prefix used when including file types")
+	cc.MarkFlagRequired("output")
 
 	return cc
 }
@@ -75,6 +142,7 @@ The --prefix flag set a prefix for the type folders. The default prefix is "__".
 func newCmdList() *cobra.Command {
 
 	listCmd := &list.Command{}
+	query, preset := "", ""
 
 	cc := &cobra.Command{
 		Use:   "list <dump file>",
@@ -84,16 +152,31 @@ be filtered by the type of object, the name of the object, the device id or
 path, and the properties of the object. The output can be written to a file in
 text, csv or xlsx formats.
 
-The output file can be specified with the --output flag. If the file extension
-is .csv, then the output will be in csv format. If the file extension is .xlsx,
-then the output will be in xlsx format. If the file extension is not recognized,
-then the output will be written as a plain text. If the output is not specified
-then the output is to the console.
+The output file can be specified with the --output flag. The format of the
+output is given with the --format flag: text, csv, tsv, xlsx, json, ndjson,
+markdown, html or yaml. If the format is not given it is found from the file
+extension of the output file. If the file extension is not recognized, then the
+output will be written as a plain text. If the output is not specified then the
+output is to the console, so other formats like json can be piped to other
+tools.
+
+The xlsx workbook has the results as an excel table with filters and a frozen
+header row. The --sheet-by flag splits the results into a sheet for each value
+of a field, for example "--sheet-by Type" or "--sheet-by DeviceId", with a
+summary sheet of the counts. The field must be one of the listed fields.
 
 The fields to include in the output can be specified with the --fields flag.
 The default fields are DeviceId, Name, and Type. The fields can be any of the
 properties of the object. The flag can be specified multiple times. The flag
-can also be specified in the format of "field1,field2,field3".
+can also be specified in the format of "field1,field2,field3". Fields can use
+the '*' wildcard to include all the matching properties found in the results,
+for example "Elec*" or "*Scale*". Fields starting with '+' are appended to the
+default fields, for example "+Channel". Giving "?" lists the property names
+found in the results.
+
+The --profile flag selects a built in set of fields for a group of object
+types. The profile also filters by its object types if --types is not given.
+The profiles are inputs, outputs, numerics, programs and alarms.
 
 The types of objects to include in the output can be specified with the --types
 flag. The flag can be specified multiple times. The flag can also be specified
@@ -118,25 +201,54 @@ of the property value using patterns and the '%' as a wildcard. If no operator
 is used the parameter will try to match the substring of the Name and Path
 properties.
 
+The where filter follows SQL precedence. NOT binds tighter than AND, and AND
+binds tighter than OR, so "a=1 OR b=2 AND c=3" is "a=1 OR (b=2 AND c=3)".
+Parentheses can be used to group the expressions. The --explain flag prints
+the parsed filter with the grouping shown and exits.
+
 The result table can be sorted with the --sort flag. the fields must be in the
 fields flag. the ordering is ascending by default, descending specific order can
 be specified with ASC or DESC before the fields.
+
+Named queries, default fields for object types, output presets and column
+headers can be defined in a .dmptool.yaml configuration file in the current
+or home directory. The --query flag runs a named query and the --preset flag
+applies a named output preset. Flags given on the command line override the
+values from the configuration file.
+
+	queries:
+	  io-schedule:
+	    types: [InfinityInput, InfinityOutput]
+	    fields: [DeviceId, Name, Type, Channel]
+	    sort: [DeviceId, Channel]
+
+	> dmptool list site.dmp --query io-schedule
 `,
 		Aliases: []string{},
 		Args:    cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			listCmd.FileName = args[0]
+			if err := applyListConfig(cmd, listCmd, query, preset); err != nil {
+				fmt.Println("error:", err)
+				return
+			}
 			listCmd.Execute()
 		},
 	}
 
 	cc.Flags().StringVarP(&listCmd.OutFile, "output", "o", "", "output file to write to")
+	cc.Flags().StringVar(&listCmd.Format, "format", "", "output format: "+strings.Join(output.Names(), ", "))
+	cc.Flags().StringVar(&listCmd.SheetBy, "sheet-by", "", "field used to split the xlsx output into sheets")
 	cc.Flags().StringSliceVarP(&listCmd.Ordering, "sort", "s", []string{}, "sort ordering of fields")
 	cc.Flags().StringVarP(&listCmd.Filter, "where", "w", "", "where like filter")
+	cc.Flags().BoolVar(&listCmd.Explain, "explain", false, "print the parsed where filter and exit")
 	cc.Flags().StringSliceVarP(&listCmd.Names, "names", "n", []string{}, "filter with matching names")
 	cc.Flags().StringSliceVarP(&listCmd.Devices, "devices", "d", []string{}, "filter with matching device ids / paths")
-	cc.Flags().StringSliceVarP(&listCmd.Fields, "fields", "f", []string{"DeviceId", "Name", "Type"}, "list of fields to include")
+	cc.Flags().StringSliceVarP(&listCmd.Fields, "fields", "f", []string{}, "list of fields to include (default DeviceId,Name,Type)")
+	cc.Flags().StringVarP(&listCmd.Profile, "profile", "p", "", "built in field profile: inputs, outputs, numerics, programs, alarms")
 	cc.Flags().StringSliceVarP(&listCmd.Types, "types", "t", []string{}, "types filter")
+	cc.Flags().StringVarP(&query, "query", "q", "", "named query from the configuration file")
+	cc.Flags().StringVar(&preset, "preset", "", "named output preset from the configuration file")
 
 	return cc
 }
@@ -144,13 +256,62 @@ be specified with ASC or DESC before the fields.
 func newCmdRef() *cobra.Command {
 	cmdRef := &ref.Command{}
 	cc := &cobra.Command{
-		Use:   "ref <dump file>",
+		Use:   "ref <dump file> [dump files...]",
 		Short: "list external references in the dump file",
 		Long: `This command will list all the external references in the dump file. The
 references can be filtered by the type of reference. The output can be written
 to a file in csv or xlsx format.
 
-If the --all flag is set, all the internal and external references will be listed.
+References are resolved against the objects in the dump file, including
+aliases and names relative to the source object. Each use of a reference is
+either resolved to an object in the dump, dangling when it is within the dump
+device or relative to the source but the object is not found, or external to
+the dump device. By default only the external references are listed.
+
+Programs usually use the objects in the same folder by name, like SAT. These
+names are found in the code and listed as references to the objects. Local
+variables declared with Numeric, String, DateTime or Integer, function
+arguments declared with Arg and line labels are not references.
+
+If the --all flag is set, all the references will be listed with a Status
+column of resolved, dangling or external, or the statuses joined with a / when
+a relative reference resolves from some sources only.
+
+If the --dangling flag is set, only the dangling references are listed. These
+are typos or deleted points that should be fixed before downloading.
+
+The --target flag lists every source that uses a point, for example before
+deleting or renaming it. The target is a path and can use * and ? wildcards,
+"--target 'Site\Bldg\*\SAT'". Each use is listed with the kind of source,
+the line number and the text of the line. If none of the --code, --graphics
+or --alarms flags are set, all the sources are searched.
+
+The --graph flag writes the references as a dependency graph instead of a
+table, in Graphviz dot, graphml, mermaid or json format. The nodes are the
+objects colored by type, and the edges go from the source to the referenced
+object labelled code, graphic or alarm. The --collapse flag merges the objects
+into their controller or device to show the traffic between controllers. Use
+--all to include the references within the dump device.
+
+  dmptool ref site.dmp --all --graph dot --collapse controller -o site.dot
+
+The --site flag loads all the dump files given and checks the external
+references of each dump against the objects of all the dumps. Each reference
+is satisfied by an object in another dump, or missing when the object is not
+in any dump of the site. The references are grouped by the source and target
+controller. Wildcards in the file names are expanded, and --dangling lists
+only the missing references.
+
+  dmptool ref --site dumps/*.dmp
+
+The --stats flag reports statistics of the references instead of the list:
+the most referenced points with the number of sources using them, the programs
+with the most external references, the external references made by and made
+to each controller, and the groups of controllers that reference each other in
+a cycle. The --top flag sets the number of points and programs ranked. Combine
+with --site to get the statistics for the whole site. The --graph, --target
+and --bare flags cannot be used with --site or --stats, nor --dangling with
+--stats.
 
 If the --bare flag is set, only the references will be listed to the console.
 
@@ -161,27 +322,51 @@ references will be listed.
 
 If the --source flag is set, the source path will be included in the output.
 
-The output file can be specified with the --output flag. If the file extension
-is .csv, then the output will be in csv format. If the file extension is .xlsx, 
-then the output will be in xlsx format. If the file extension is not recognized,
-then the output will be written as a text. If the output is not specified then
-the output is to stout.
+The Access column shows if the program reads or writes the reference. Names
+assigned at the start of a statement, "Site\X\Y = ...", and the targets of
+Set and Turn On or Turn Off are writes, all other uses are reads. The --writes
+flag lists only the writes.
+
+The output file can be specified with the --output flag. The format of the
+output is given with the --format flag: text, csv, tsv, xlsx, json, ndjson,
+markdown, html or yaml. If the format is not given it is found from the file
+extension of the output file. If the file extension is not recognized, then the
+output will be written as a text. If the output is not specified then the
+output is to stdout.
+
+The --sheet-by flag splits the xlsx workbook into a sheet for each value of
+a column, for example "--sheet-by Source".
 `,
 		Args:    cobra.MinimumNArgs(1),
 		Aliases: []string{"references", "refs"},
 		Run: func(cmd *cobra.Command, args []string) {
 			if !(cmdRef.Code || cmdRef.Graphics || cmdRef.Alarms) {
 				cmdRef.Code = true
+				if cmdRef.Target != "" {
+					cmdRef.Graphics = true
+					cmdRef.Alarms = true
+				}
 			}
 			cmdRef.FileName = args[0]
+			cmdRef.FileNames = args
 			cmdRef.Execute()
 		},
 	}
 
 	cc.Flags().StringVarP(&cmdRef.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringVar(&cmdRef.Format, "format", "", "output format: "+strings.Join(output.Names(), ", "))
+	cc.Flags().StringVar(&cmdRef.SheetBy, "sheet-by", "", "column used to split the xlsx output into sheets")
 	cc.Flags().BoolVarP(&cmdRef.Bare, "bare", "b", false, "return just the references")
 	cc.Flags().BoolVarP(&cmdRef.All, "all", "a", false, "return all the references")
+	cc.Flags().BoolVarP(&cmdRef.Dangling, "dangling", "d", false, "return only the references to missing objects in the dump device")
 	cc.Flags().BoolVarP(&cmdRef.Sources, "source", "s", false, "show the source path")
+	cc.Flags().StringVar(&cmdRef.Graph, "graph", "", "write a dependency graph: dot, graphml, mermaid, json")
+	cc.Flags().StringVar(&cmdRef.Collapse, "collapse", "", "collapse the graph nodes to the controller or device")
+	cc.Flags().BoolVarP(&cmdRef.Writes, "writes", "w", false, "return only the references written by the programs")
+	cc.Flags().BoolVar(&cmdRef.Stats, "stats", false, "report the reference statistics")
+	cc.Flags().IntVar(&cmdRef.Top, "top", 10, "number of points and programs ranked by --stats")
+	cc.Flags().BoolVar(&cmdRef.Site, "site", false, "check the references across all the dump files of the site")
+	cc.Flags().StringVar(&cmdRef.Target, "target", "", "list the sources using the target path, * and ? wildcards allowed")
 	cc.Flags().BoolVarP(&cmdRef.Code, "code", "c", false, "include the script code sources (default)")
 	cc.Flags().BoolVarP(&cmdRef.Graphics, "graphics", "g", false, "include the graphics sources")
 	cc.Flags().BoolVarP(&cmdRef.Alarms, "alarms", "l", false, "include the alarm link sources")
@@ -190,6 +375,392 @@ the output is to stout.
 	return cc
 }
 
+func newCmdFields() *cobra.Command {
+	cmdFields := &fields.Command{}
+	cc := &cobra.Command{
+		Use:   "fields <dump file>",
+		Short: "report the properties of each object type",
+		Long: `This command will report the properties found for each object type in the
+dump file. For each property of the type the report shows the number of
+objects with the property, the number of distinct values, the most common
+sample values and the inferred data type.
+
+The inferred data types are int, float, timestamp, path, enum (a few distinct
+values that repeat), text (multi line values) and string.
+
+The types of objects to include in the report can be specified with the
+--types flag. The number of sample values can be set with the --samples flag.
+`,
+		Args:    cobra.MinimumNArgs(1),
+		Aliases: []string{"schema", "properties"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdFields.FileName = args[0]
+			cmdFields.Execute()
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdFields.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringSliceVarP(&cmdFields.Types, "types", "t", []string{}, "types filter")
+	cc.Flags().IntVarP(&cmdFields.Samples, "samples", "n", 3, "number of sample values")
+	return cc
+}
+
+func newCmdAlarms() *cobra.Command {
+	cmdAlarms := &alarms.Command{}
+	cc := &cobra.Command{
+		Use:   "alarms <dump file>",
+		Short: "report the alarm links of each point",
+		Long: `This command will list the alarm links of each point in the dump file. For
+each alarm link slot the report shows the point, the slot id, the alarm
+enrollment, whether the link is enabled, and the properties of the enrollment.
+
+The enrollment properties listed can be set with the --fields flag, by
+default all the single line properties of the enrollments are listed.
+
+The Issue column flags links to enrollments that are not in the dump file, and
+disabled links on critical types. The critical types are set with the
+--critical flag, the default is InfinityInput and InfinityOutput. The --issues
+flag lists only the links with an issue.
+
+The output file can be specified with the --output flag and the format with
+the --format flag, as for the list command.
+`,
+		Args:    cobra.MinimumNArgs(1),
+		Aliases: []string{"alarm"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdAlarms.FileName = args[0]
+			cmdAlarms.Execute()
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdAlarms.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringVar(&cmdAlarms.Format, "format", "", "output format: "+strings.Join(output.Names(), ", "))
+	cc.Flags().StringVar(&cmdAlarms.SheetBy, "sheet-by", "", "column used to split the xlsx output into sheets")
+	cc.Flags().StringSliceVarP(&cmdAlarms.Fields, "fields", "f", []string{}, "enrollment properties to list")
+	cc.Flags().StringSliceVar(&cmdAlarms.Critical, "critical", alarms.DefaultCritical, "types where disabled alarm links are an issue")
+	cc.Flags().BoolVar(&cmdAlarms.Issues, "issues", false, "list only the links with an issue")
+	return cc
+}
+
+func newCmdGraphics() *cobra.Command {
+	cmdGraphics := &graphics.Command{}
+	cc := &cobra.Command{
+		Use:   "graphics <dump file>",
+		Short: "report the panel objects of the graphics",
+		Long: `This command will list the panel objects in the PanelObjectList of each
+graphics panel in the dump file. For each panel object the report shows the
+type, the bound path, the position and the display format.
+
+Paths to other graphics are navigation links, all other paths are points. Each
+path is resolved against the objects in the dump file and is resolved, missing
+when it is in the dump device but the object is not found, or external.
+
+The layout of the PanelObjectList is not documented. The list is read one object
+per line and the values are found by their names, Type, X, Y and Format, or
+else by their form. Check the Line column against the dump when a value looks
+wrong.
+
+If the --missing flag is set, only the panel objects bound to missing points
+are listed. If the --links flag is set, only the links between the panels are
+listed.
+
+The output file can be specified with the --output flag and the format with
+the --format flag, as for the list command.
+`,
+		Args:    cobra.MinimumNArgs(1),
+		Aliases: []string{"graphic", "panels"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdGraphics.FileName = args[0]
+			cmdGraphics.Execute()
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdGraphics.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringVar(&cmdGraphics.Format, "format", "", "output format: "+strings.Join(output.Names(), ", "))
+	cc.Flags().StringVar(&cmdGraphics.SheetBy, "sheet-by", "", "column used to split the xlsx output into sheets")
+	cc.Flags().BoolVar(&cmdGraphics.Missing, "missing", false, "list only the panel objects bound to missing points")
+	cc.Flags().BoolVar(&cmdGraphics.Links, "links", false, "list only the links between the panels")
+	return cc
+}
+
+func newCmdLint() *cobra.Command {
+	cmdLint := &lint.Command{}
+	cc := &cobra.Command{
+		Use:   "lint <dump file>",
+		Short: "check the programs for common Plain English mistakes",
+		Long: `This command will check the code of every Program, InfinityProgram and
+InfinityFunction in the dump file and report:
+
+	syntax                 code that could not be parsed
+	undefined-label        Goto to a line label that is not defined
+	unreachable-label      line labels never reached by a Goto or falling through
+	unused-local           local variables declared but not used
+	unused-argument        function arguments not used
+	write-input            writes to input points
+	missing-reference      references to objects missing from the dump device
+	fallthrough            fall through programs without a Goto or Stop at the end
+	suspicious-comparison  comparisons of a value with itself, equality with
+	                       decimal numbers and the order of On and Off
+
+Each finding has a severity of error, warning or note. The --severity flag
+sets the lowest severity reported.
+
+The output file can be specified with the --output flag and the format with
+the --format flag, as for the list command. The sarif format, or an output file
+with the .sarif extension, writes a SARIF log with the locations of the files
+written by the pe command.
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdLint.FileName = args[0]
+			cmdLint.Execute()
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdLint.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringVar(&cmdLint.Format, "format", "", "output format: "+strings.Join(append(output.Names(), lint.FormatSARIF), ", "))
+	cc.Flags().StringVar(&cmdLint.SheetBy, "sheet-by", "", "column used to split the xlsx output into sheets")
+	cc.Flags().StringVar(&cmdLint.Severity, "severity", "note", "lowest severity reported: note, warning, error")
+	return cc
+}
+
+func newCmdFmt() *cobra.Command {
+	cmdFmt := &format.Command{}
+	cc := &cobra.Command{
+		Use:   "fmt <dump file | pe files | directories>",
+		Short: "format the code of the programs",
+		Long: `This command will format the Plain English code of the programs. The
+keywords are capitalized, the statements are indented under the line labels,
+the lines in If, Select Case, For, While and Repeat blocks are indented, and
+the operators have a single space around them. Comments, blank lines and the
+line endings are kept.
+
+The arguments can be the .pe files written by the pe command, directories of
+.pe files, or dump files. The ByteCode of the programs in a dump file is
+formatted in place and the rest of the dump is written back unchanged.
+
+The files and programs that are not formatted are listed. With the --check
+flag no files are changed and the command exits with status 1 if any program
+is not formatted.
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdFmt.Paths = args
+			cmdFmt.Execute()
+			if cmdFmt.Check && cmdFmt.Unformatted > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cc.Flags().BoolVar(&cmdFmt.Check, "check", false, "list the programs not formatted without changing the files")
+	return cc
+}
+
+func newCmdExport() *cobra.Command {
+	cmdExport := &pe.Command{Objects: true}
+	cc := &cobra.Command{
+		Use:   "export <dump file> [output directory]",
+		Short: "export every object into individual files",
+		Long: `This command will export every object of the dump file into the same tree
+of files as the pe command, so a whole controller can be browsed and searched
+as a file system.
+
+	.pe       the code of the programs and functions
+	.json     the properties of each object, or .yaml with --format yaml
+	.pan      the PanelObjectList of the graphics panels
+	.csv      the tables of the dictionaries
+
+The --types flag limits the export to the object types, the type name of the
+dictionaries is Dictionary. The --type, --flatten, --separator and --prefix
+flags set the layout of the files as for the pe command, and --sync updates
+a previous export as for the pe command.
+`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdExport.FileName = args[0]
+			if len(args) > 1 {
+				cmdExport.OutDir = args[1]
+			}
+			cmdExport.Execute()
+		},
+	}
+
+	cf := cc.Flags()
+	cf.StringVar(&cmdExport.Format, "format", "json", "format of the property files: json, yaml")
+	cf.StringSliceVar(&cmdExport.Types, "types", []string{}, "types filter")
+	cf.BoolVarP(&cmdExport.TypeFolder, "type", "t", false, "include the typename as a folder for the files")
+	cf.BoolVarP(&cmdExport.Flatten, "flatten", "f", false, "flatten the file path to a single name")
+	cf.StringVar(&cmdExport.FlattenSep, "separator", "~", "separator used when flattening file paths")
+	cf.StringVar(&cmdExport.TypePrefix, "prefix", "__", "prefix used when including file types")
+	cf.BoolVar(&cmdExport.Manifest, "manifest", false, "write a manifest.json listing the files with their checksums")
+	cf.BoolVar(&cmdExport.Sync, "sync", false, "write only the changed files and remove the files no longer exported")
+	return cc
+}
+
+func newCmdSnapshot() *cobra.Command {
+	cmdSnapshot := &pe.Command{
+		TypePrefix: "__",
+		FlattenSep: "~",
+		Sidecar:    true,
+		Manifest:   true,
+		Objects:    true,
+		Sync:       true,
+	}
+	cc := &cobra.Command{
+		Use:   "snapshot <dump file> <directory>",
+		Short: "export the programs and objects to a directory kept in git",
+		Long: `This command will export the programs of the dump file as the pe command
+does, with a .pe.json file of the properties of each program, and a .json
+file of the properties of every other object. The files are synced: only the
+changed files are written and the files of objects no longer in the dump are
+removed, so when the directory is kept in git the history of the commits is
+the change log of the site.
+
+	dmptool snapshot site.dmp site-repo
+	git -C site-repo add -A
+	git -C site-repo commit -m "site visit"
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdSnapshot.FileName = args[0]
+			cmdSnapshot.OutDir = args[1]
+			cmdSnapshot.Execute()
+		},
+	}
+
+	cc.Flags().BoolVarP(&cmdSnapshot.TypeFolder, "type", "t", false, "include the typename as a folder for the files")
+	return cc
+}
+
+func newCmdCalls() *cobra.Command {
+	cmdCalls := &calls.Command{}
+	cc := &cobra.Command{
+		Use:   "calls <dump file>",
+		Short: "display the calls of the InfinityFunctions",
+		Long: `This command will build the graph of the calls among the programs and
+functions of the dump file. The number of arguments of each function is the
+highest Arg number declared in its code. The graph is written as a tree of
+the functions called by each program, with the number of calls, followed by
+the issues found:
+
+	unused function       functions not called by any program or function
+	recursion             functions calling themselves, directly or in a cycle
+	wrong argument count  calls with a number of arguments different from
+	                      the Arg declarations of the function
+	syntax error          statements that could not be parsed, the calls in
+	                      them are not in the graph
+
+The --graph dot flag writes the graph in Graphviz dot format instead.
+
+	dmptool calls site.dmp --graph dot -o calls.dot
+	dot -Tsvg calls.dot -o calls.svg
+
+The --issues flag writes only the issues, in the format of the --format flag
+as for the list command.
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdCalls.FileName = args[0]
+			cmdCalls.Execute()
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdCalls.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringVar(&cmdCalls.Graph, "graph", calls.GraphTree, "graph format: tree, dot")
+	cc.Flags().BoolVar(&cmdCalls.Issues, "issues", false, "write only the issues")
+	cc.Flags().StringVar(&cmdCalls.Format, "format", "", "output format of the issues: "+strings.Join(output.Names(), ", "))
+	cc.Flags().StringVar(&cmdCalls.SheetBy, "sheet-by", "", "column used to split the xlsx output into sheets")
+	return cc
+}
+
+func newCmdMetrics() *cobra.Command {
+	cmdMetrics := &metrics.Command{}
+	cc := &cobra.Command{
+		Use:   "metrics <dump file>",
+		Short: "report the size and complexity of the programs",
+		Long: `This command will report for every Program, InfinityProgram and
+InfinityFunction in the dump file:
+
+	Lines         lines with code
+	Comments      lines with a comment
+	CommentRatio  comment lines per line of code
+	Labels        line labels
+	Gotos         Goto statements
+	Complexity    cyclomatic complexity, one more than the number of If, Case,
+	              For, While, Repeat, And and Or
+	References    distinct external references
+	Locals        local variables declared
+	SyntaxErrors  statements that could not be parsed, the measures above
+	              leave out what is in them
+
+The result table can be sorted with the --sort flag using the names above,
+with DESC before the name for a descending order.
+
+	> dmptool metrics site.dmp --sort "desc Complexity" -o metrics.xlsx
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdMetrics.FileName = args[0]
+			cmdMetrics.Execute()
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdMetrics.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().StringVar(&cmdMetrics.Format, "format", "", "output format: "+strings.Join(output.Names(), ", "))
+	cc.Flags().StringVar(&cmdMetrics.SheetBy, "sheet-by", "", "column used to split the xlsx output into sheets")
+	cc.Flags().StringSliceVarP(&cmdMetrics.Ordering, "sort", "s", []string{}, "sort ordering of the columns")
+	return cc
+}
+
+func newCmdGrep() *cobra.Command {
+	cmdGrep := &grep.Command{}
+	cc := &cobra.Command{
+		Use:   "grep <dump file> <pattern>",
+		Short: "search the code of the programs",
+		Long: `This command will search the ByteCode of the objects in the dump file for
+the regular expression pattern, and write each matching line as
+
+	path:line: text
+
+The --context flag writes the lines around the matches as path-line- text,
+with -- between the groups of lines. The --word flag matches whole words only,
+--ignore-case ignores the case of the letters, and --no-comments skips the
+comments of the code.
+
+The --panels flag also searches the PanelObjectList of the graphics panels and
+the --props flag other properties, the lines of these are named path.Property.
+
+The command exits with status 1 if no line matched, and with status 2 if the
+pattern is not valid, the dump file could not be read or the output could not
+be written.
+
+	> dmptool grep site.dmp -w -i "ZoneTemp" -C 2
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdGrep.FileName = args[0]
+			cmdGrep.Pattern = args[1]
+			cmdGrep.Execute()
+			switch {
+			case cmdGrep.Err != nil:
+				os.Exit(2)
+			case cmdGrep.Matches == 0:
+				os.Exit(1)
+			}
+		},
+	}
+
+	cc.Flags().StringVarP(&cmdGrep.OutFile, "output", "o", "", "output file to write to. default is stdout")
+	cc.Flags().IntVarP(&cmdGrep.Context, "context", "C", 0, "number of lines written before and after the matches")
+	cc.Flags().BoolVarP(&cmdGrep.Word, "word", "w", false, "match whole words only")
+	cc.Flags().BoolVarP(&cmdGrep.IgnoreCase, "ignore-case", "i", false, "ignore the case of the letters")
+	cc.Flags().BoolVar(&cmdGrep.NoComments, "no-comments", false, "skip the comments of the code")
+	cc.Flags().BoolVar(&cmdGrep.Panels, "panels", false, "also search the PanelObjectList of the graphics panels")
+	cc.Flags().StringSliceVar(&cmdGrep.Properties, "props", []string{}, "other properties to search")
+	return cc
+}
+
 func newCmdTree() *cobra.Command {
 	cmdTree := &tree.Command{}
 	cc := &cobra.Command{
@@ -227,11 +798,22 @@ func main() {
 		Short: "continuum dump file tool",
 		Args:  cobra.MinimumNArgs(2),
 	}
+	cc.PersistentFlags().StringVar(&configFile, "config", "", "configuration file (default is ./"+config.FileName+" or ~/"+config.FileName+")")
 	cc.AddCommand(
 		newCmdPE(),
 		newCmdRef(),
 		newCmdTree(),
 		newCmdList(),
+		newCmdFields(),
+		newCmdAlarms(),
+		newCmdGraphics(),
+		newCmdLint(),
+		newCmdFmt(),
+		newCmdSnapshot(),
+		newCmdExport(),
+		newCmdCalls(),
+		newCmdMetrics(),
+		newCmdGrep(),
 		newCmdVersion(),
 	)
 	cc.Execute()
@@ -9,6 +9,7 @@ require (
 	github.com/xuri/excelize/v2 v2.9.0
 	golang.org/x/exp v0.0.0-20250106191152-7588d65b2ba8
 	golang.org/x/sys v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,181 @@
+// Package config loads the dmptool project configuration file.
+//
+// The configuration file is a yaml file named .dmptool.yaml. The file is
+// looked for in the current directory and then in the home directory.
+//
+//	headers:
+//	  DeviceId: Device
+//
+//	fields:
+//	  InfinityInput: [DeviceId, Name, Channel, ElecType]
+//
+//	presets:
+//	  report:
+//	    output: report.xlsx
+//	  wiki:
+//	    format: markdown
+//
+//	queries:
+//	  io-schedule:
+//	    types: [InfinityInput, InfinityOutput]
+//	    fields: [DeviceId, Name, Type, Channel]
+//	    where: Channel ISNOTNULL
+//	    sort: [DeviceId, Channel]
+//	    preset: report
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file
+const FileName = ".dmptool.yaml"
+
+// Query is a named set of list options
+type Query struct {
+	Types   []string          `yaml:"types"`
+	Names   []string          `yaml:"names"`
+	Devices []string          `yaml:"devices"`
+	Fields  []string          `yaml:"fields"`
+	Where   string            `yaml:"where"`
+	Sort    []string          `yaml:"sort"`
+	Output  string            `yaml:"output"`
+	Preset  string            `yaml:"preset"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Preset is a named set of output options
+type Preset struct {
+	Output  string            `yaml:"output"`
+	Format  string            `yaml:"format"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Config is the contents of the configuration file
+type Config struct {
+	// Path is the file the configuration was loaded from.
+	// empty if no file was found.
+	Path string `yaml:"-"`
+
+	// Headers maps the field names to the column headers
+	Headers map[string]string `yaml:"headers"`
+
+	// Fields is the default fields for each object type
+	Fields map[string][]string `yaml:"fields"`
+
+	// Presets is the named output presets
+	Presets map[string]*Preset `yaml:"presets"`
+
+	// Queries is the named list queries
+	Queries map[string]*Query `yaml:"queries"`
+}
+
+// Load finds and loads the configuration file. The current directory
+// is searched first and then the home directory. If no file is found
+// an empty configuration is returned.
+func Load() (*Config, error) {
+	dirs := make([]string, 0, 2)
+	if d, err := os.Getwd(); err == nil {
+		dirs = append(dirs, d)
+	}
+	if d, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, d)
+	}
+	for _, d := range dirs {
+		name := filepath.Join(d, FileName)
+		if _, err := os.Stat(name); err == nil {
+			return LoadFile(name)
+		}
+	}
+	return &Config{}, nil
+}
+
+// LoadFile loads the configuration from the named file.
+func LoadFile(name string) (*Config, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	cfg.Path = name
+	return cfg, nil
+}
+
+func parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	for name, q := range cfg.Queries {
+		if q == nil {
+			return nil, fmt.Errorf("query %q is empty", name)
+		}
+		if q.Preset != "" {
+			if _, ok := cfg.Presets[q.Preset]; !ok {
+				return nil, fmt.Errorf("query %q: unknown preset %q", name, q.Preset)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+var (
+	ErrUnknownQuery  = errors.New("unknown query")
+	ErrUnknownPreset = errors.New("unknown preset")
+)
+
+// Query returns the named query
+func (c *Config) Query(name string) (*Query, error) {
+	q, ok := c.Queries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownQuery, name)
+	}
+	return q, nil
+}
+
+// Preset returns the named output preset
+func (c *Config) Preset(name string) (*Preset, error) {
+	p, ok := c.Presets[name]
+	if !ok || p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPreset, name)
+	}
+	return p, nil
+}
+
+// TypeFields returns the default fields for the types. The fields are
+// only returned when all the types share the same default fields.
+func (c *Config) TypeFields(types []string) []string {
+	if len(types) == 0 {
+		return nil
+	}
+	fields, ok := c.Fields[types[0]]
+	if !ok {
+		return nil
+	}
+	for _, t := range types[1:] {
+		if !slices.Equal(fields, c.Fields[t]) {
+			return nil
+		}
+	}
+	return fields
+}
+
+// ColumnHeaders merges the header maps with later maps taking priority.
+func ColumnHeaders(maps ...map[string]string) map[string]string {
+	headers := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			headers[k] = v
+		}
+	}
+	return headers
+}
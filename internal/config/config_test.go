@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const testConfig = `
+headers:
+  DeviceId: Device
+
+fields:
+  InfinityInput: [DeviceId, Name, Channel]
+  InfinityOutput: [DeviceId, Name, Channel]
+  InfinityNumeric: [DeviceId, Name, Value]
+
+presets:
+  report:
+    output: report.xlsx
+    headers:
+      Name: Point
+
+queries:
+  io-schedule:
+    types: [InfinityInput, InfinityOutput]
+    fields: [DeviceId, Name, Type, Channel]
+    where: Channel ISNOTNULL
+    sort: [DeviceId, Channel]
+    preset: report
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	q, err := cfg.Query("io-schedule")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !slices.Equal(q.Types, []string{"InfinityInput", "InfinityOutput"}) {
+		t.Errorf("expected types got %v", q.Types)
+	}
+	if q.Where != "Channel ISNOTNULL" {
+		t.Errorf("expected where got %q", q.Where)
+	}
+
+	p, err := cfg.Preset(q.Preset)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if p.Output != "report.xlsx" {
+		t.Errorf("expected report.xlsx got %q", p.Output)
+	}
+
+	if _, err := cfg.Query("missing"); !errors.Is(err, ErrUnknownQuery) {
+		t.Errorf("expected unknown query error got %v", err)
+	}
+}
+
+func TestParseUnknownPreset(t *testing.T) {
+	_, err := parse([]byte(`
+queries:
+  q1:
+    preset: missing
+`))
+	if err == nil {
+		t.Error("expected an error for the missing preset")
+	}
+}
+
+func TestTypeFields(t *testing.T) {
+	cfg, err := parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		types    []string
+		expected []string
+	}{
+		{"no types", nil, nil},
+		{"single type", []string{"InfinityNumeric"}, []string{"DeviceId", "Name", "Value"}},
+		{"same fields", []string{"InfinityInput", "InfinityOutput"}, []string{"DeviceId", "Name", "Channel"}},
+		{"different fields", []string{"InfinityInput", "InfinityNumeric"}, nil},
+		{"unknown type", []string{"Program"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := cfg.TypeFields(test.types)
+			if !slices.Equal(result, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestColumnHeaders(t *testing.T) {
+	h := ColumnHeaders(
+		map[string]string{"DeviceId": "Device", "Name": "Name"},
+		nil,
+		map[string]string{"Name": "Point"},
+	)
+	if h["DeviceId"] != "Device" || h["Name"] != "Point" {
+		t.Errorf("unexpected headers %v", h)
+	}
+}
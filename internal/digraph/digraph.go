@@ -0,0 +1,76 @@
+// Package digraph has the algorithms on the directed graphs of the
+// references and calls, the graphs are maps of the edges of each node,
+// and the quoting of their Graphviz dot output.
+package digraph
+
+import (
+	"slices"
+	"strings"
+)
+
+// Cycles returns the strongly connected components with more than one
+// node, using Tarjan's algorithm. The components and their nodes are
+// sorted.
+func Cycles(edges map[string][]string) [][]string {
+	nodes := make([]string, 0, len(edges))
+	for n := range edges {
+		nodes = append(nodes, n)
+	}
+	slices.Sort(nodes)
+
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	groups := make([][]string, 0)
+
+	var connect func(v string)
+	connect = func(v string) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edges[v] {
+			if _, ok := index[w]; !ok {
+				connect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			group := make([]string, 0)
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				group = append(group, w)
+				if w == v {
+					break
+				}
+			}
+			if len(group) > 1 {
+				slices.Sort(group)
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		if _, ok := index[n]; !ok {
+			connect(n)
+		}
+	}
+	slices.SortFunc(groups, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	return groups
+}
+
+// DotQuote quotes the string as a Graphviz dot ID, the \ of the paths and
+// the quotes are escaped.
+func DotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,35 @@
+package digraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCycles(t *testing.T) {
+	edges := map[string][]string{
+		"a": {"b"},
+		"b": {"c", "d"},
+		"c": {"a"},
+		"d": {"e"},
+		"e": {"d"},
+		"f": {"a"},
+	}
+	got := Cycles(edges)
+	expected := [][]string{{"a", "b", "c"}, {"d", "e"}}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+	for i := range expected {
+		if !slices.Equal(got[i], expected[i]) {
+			t.Errorf("expected %v got %v", expected[i], got[i])
+		}
+	}
+}
+
+func TestDotQuote(t *testing.T) {
+	got := DotQuote(`NUSite\Ctrl1\"SAT"`)
+	expected := `"NUSite\\Ctrl1\\\"SAT\""`
+	if got != expected {
+		t.Errorf("expected %s got %s", expected, got)
+	}
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlPage is a standalone page with the table. Clicking a column
+// header sorts the rows by the column, numbers are sorted by value.
+var htmlPage = template.Must(template.New("table").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>dmptool</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; white-space: pre; }
+th { background: #eee; cursor: pointer; position: sticky; top: 0; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:nth-child(even) td { background: #f8f8f8; }
+</style>
+</head>
+<body>
+<table>
+<thead>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+<script>
+document.querySelectorAll("th").forEach(function (th, col) {
+  th.addEventListener("click", function () {
+    var tbody = th.closest("table").tBodies[0];
+    var desc = th.classList.contains("asc");
+    th.parentNode.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(desc ? "desc" : "asc");
+    var rows = Array.prototype.slice.call(tbody.rows);
+    var collator = new Intl.Collator(undefined, { numeric: true, sensitivity: "base" });
+    rows.sort(function (a, b) {
+      var x = a.cells[col].textContent, y = b.cells[col].textContent;
+      var nx = Number(x), ny = Number(y);
+      var r = (x !== "" && y !== "" && !isNaN(nx) && !isNaN(ny)) ? nx - ny : collator.compare(x, y);
+      return desc ? -r : r;
+    });
+    rows.forEach(function (r) { tbody.appendChild(r); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// writeHTML writes the table as a standalone html page with sortable columns
+func writeHTML(w io.Writer, t *Table) error {
+	return htmlPage.Execute(w, t)
+}
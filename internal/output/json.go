@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// appendRecord appends the record as a json object with the keys
+// in the column order.
+func appendRecord(b []byte, rec [][2]string) ([]byte, error) {
+	b = append(b, '{')
+	for i, kv := range rec {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		k, err := json.Marshal(kv[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	return append(b, '}'), nil
+}
+
+// writeJSON writes the table as an indented json array of objects
+func writeJSON(w io.Writer, t *Table) error {
+	b := []byte{'['}
+	var err error
+	for i, rec := range t.records() {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if b, err = appendRecord(b, rec); err != nil {
+			return err
+		}
+	}
+	b = append(b, ']')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err = out.WriteTo(w)
+	return err
+}
+
+// writeNDJSON writes the table as a json object on each line
+func writeNDJSON(w io.Writer, t *Table) error {
+	for _, rec := range t.records() {
+		b, err := appendRecord(nil, rec)
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML writes the table as a yaml list of mappings with the keys
+// in the column order.
+func writeYAML(w io.Writer, t *Table) error {
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, rec := range t.records() {
+		m := &yaml.Node{Kind: yaml.MappingNode}
+		for _, kv := range rec {
+			m.Content = append(m.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: kv[0]},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: kv[1]},
+			)
+		}
+		list.Content = append(list.Content, m)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(list); err != nil {
+		return err
+	}
+	return enc.Close()
+}
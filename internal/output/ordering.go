@@ -1,4 +1,4 @@
-package list
+package output
 
 import (
 	"errors"
@@ -68,7 +68,10 @@ var (
 	errOrderbyUnknownField      = errors.New("orderby unknown field")
 )
 
-func reorder(orders []string, fields []string, table [][]string) error {
+// Reorder sorts the rows of the table by the orders. Each order is a field
+// name, optionally after asc or desc. The numbers and the digits in the
+// values are compared by their value.
+func Reorder(orders []string, fields []string, table [][]string) error {
 
 	lc_fields := make([]string, len(fields))
 	for i := range fields {
@@ -85,7 +88,7 @@ func reorder(orders []string, fields []string, table [][]string) error {
 		case 2:
 			os[i].name = do[1]
 			switch do[0] {
-			case "acs":
+			case "asc", "acs":
 				os[i].desc = false
 			case "desc":
 				os[i].desc = true
@@ -137,3 +140,7 @@ func partitionDigits(s string) []string {
 	r = append(r, s[left:])
 	return r
 }
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
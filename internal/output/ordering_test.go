@@ -1,4 +1,4 @@
-package list
+package output
 
 import (
 	"reflect"
@@ -144,6 +144,14 @@ func TestReorder(t *testing.T) {
 			expected:  [][]string{{"2", "Alice"}, {"1", "Bob"}, {"3", "Charlie"}},
 			expectErr: nil,
 		},
+		{
+			name:      "Valid reorder asc",
+			orders:    []string{"asc name"},
+			fields:    []string{"id", "name"},
+			table:     [][]string{{"1", "Bob"}, {"2", "Alice"}, {"3", "Charlie"}},
+			expected:  [][]string{{"2", "Alice"}, {"1", "Bob"}, {"3", "Charlie"}},
+			expectErr: nil,
+		},
 		{
 			name:      "Valid reorder descending",
 			orders:    []string{"desc name"},
@@ -193,14 +201,14 @@ func TestReorder(t *testing.T) {
 				tableCopy[i] = append([]string{}, test.table[i]...)
 			}
 
-			err := reorder(test.orders, test.fields, tableCopy)
+			err := Reorder(test.orders, test.fields, tableCopy)
 			if err != test.expectErr {
-				t.Errorf("reorder() error = %v; want %v", err, test.expectErr)
+				t.Errorf("Reorder() error = %v; want %v", err, test.expectErr)
 				return
 			}
 
 			if !reflect.DeepEqual(tableCopy, test.expected) {
-				t.Errorf("reorder() = %v; want %v", tableCopy, test.expected)
+				t.Errorf("Reorder() = %v; want %v", tableCopy, test.expected)
 			}
 		})
 	}
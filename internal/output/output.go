@@ -0,0 +1,195 @@
+// Package output writes tables of results in the supported file formats.
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an output file format
+type Format string
+
+const (
+	Text     Format = "text"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	XLSX     Format = "xlsx"
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+	YAML     Format = "yaml"
+)
+
+// formats maps the format names and file extensions to the formats
+var formats = map[string]Format{
+	"text":     Text,
+	"txt":      Text,
+	"csv":      CSV,
+	"tsv":      TSV,
+	"xlsx":     XLSX,
+	"json":     JSON,
+	"ndjson":   NDJSON,
+	"jsonl":    NDJSON,
+	"markdown": Markdown,
+	"md":       Markdown,
+	"html":     HTML,
+	"htm":      HTML,
+	"yaml":     YAML,
+	"yml":      YAML,
+}
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrNoOutputFile  = errors.New("an output file is required")
+)
+
+// Names returns the names of the supported formats
+func Names() []string {
+	return []string{
+		string(Text),
+		string(CSV),
+		string(TSV),
+		string(XLSX),
+		string(JSON),
+		string(NDJSON),
+		string(Markdown),
+		string(HTML),
+		string(YAML),
+	}
+}
+
+// Lookup returns the format for the format name. If the name is empty
+// the format is found from the extension of the file name. Files with
+// unrecognized extensions, and stdout, are written as text. The xlsx
+// format is binary and is not written to stdout.
+func Lookup(name string, fileName string) (Format, error) {
+	if name != "" {
+		f, ok := formats[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("%w %q, expected one of %s", ErrUnknownFormat, name, strings.Join(Names(), ", "))
+		}
+		if f == XLSX && fileName == "" {
+			return "", fmt.Errorf("%w for the %s format, use -o", ErrNoOutputFile, f)
+		}
+		return f, nil
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	if f, ok := formats[ext]; ok {
+		return f, nil
+	}
+	return Text, nil
+}
+
+// Table is a table of results with a header row
+type Table struct {
+	Header []string
+	Rows   [][]string
+
+	// SheetBy is the column used to split the rows into
+	// separate sheets for the formats that have sheets.
+	SheetBy string
+}
+
+// Column returns the index of the named column or -1 if the table does
+// not have the column. The name is not case sensitive.
+func (t *Table) Column(name string) int {
+	for i, h := range t.Header {
+		if strings.EqualFold(h, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Widths returns the max width of each column including the header
+func (t *Table) Widths() []int {
+	ws := make([]int, len(t.Header))
+	for i, n := range t.Header {
+		ws[i] = max(ws[i], len(n))
+	}
+	for _, row := range t.Rows {
+		for i, n := range row {
+			if i < len(ws) {
+				ws[i] = max(ws[i], len(n))
+			}
+		}
+	}
+	return ws
+}
+
+// records returns the rows as a list of field name value pairs
+// in the column order.
+func (t *Table) records() [][][2]string {
+	records := make([][][2]string, len(t.Rows))
+	for r, row := range t.Rows {
+		rec := make([][2]string, len(t.Header))
+		for i, h := range t.Header {
+			rec[i][0] = h
+			if i < len(row) {
+				rec[i][1] = row[i]
+			}
+		}
+		records[r] = rec
+	}
+	return records
+}
+
+// Write writes the table to w in the format
+func Write(w io.Writer, f Format, t *Table) error {
+	switch f {
+	case Text:
+		return writeText(w, t)
+	case CSV:
+		return writeCSV(w, t)
+	case TSV:
+		return writeTSV(w, t)
+	case XLSX:
+		return writeXlsx(w, t)
+	case JSON:
+		return writeJSON(w, t)
+	case NDJSON:
+		return writeNDJSON(w, t)
+	case Markdown:
+		return writeMarkdown(w, t)
+	case HTML:
+		return writeHTML(w, t)
+	case YAML:
+		return writeYAML(w, t)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownFormat, f)
+	}
+}
+
+// WriteFile writes the table to the named file in the format. If the
+// file name is empty the table is written to stdout.
+func WriteFile(fileName string, f Format, t *Table) error {
+	return Create(fileName, func(w io.Writer) error {
+		return Write(w, f, t)
+	})
+}
+
+// Create creates the named file and calls fn to write the contents.
+// If the file name is empty fn writes to stdout.
+func Create(fileName string, fn func(w io.Writer) error) error {
+	if fileName == "" {
+		return fn(os.Stdout)
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create file: %w", err)
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testTable = &Table{
+	Header: []string{"Name", "Channel"},
+	Rows: [][]string{
+		{"SAT", "3"},
+		{"O|A\tT", "4"},
+	},
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		file     string
+		expected Format
+	}{
+		{"default", "", "", Text},
+		{"csv extension", "", "out.CSV", CSV},
+		{"xlsx extension", "", "out.xlsx", XLSX},
+		{"unknown extension", "", "out.dat", Text},
+		{"markdown extension", "", "out.md", Markdown},
+		{"format flag", "json", "", JSON},
+		{"format overrides extension", "ndjson", "out.csv", NDJSON},
+		{"format alias", "yml", "", YAML},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Lookup(test.format, test.file)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if result != test.expected {
+				t.Errorf("expected %q got %q", test.expected, result)
+			}
+		})
+	}
+
+	if _, err := Lookup("pdf", ""); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected unknown format error got %v", err)
+	}
+	if _, err := Lookup("xlsx", ""); !errors.Is(err, ErrNoOutputFile) {
+		t.Errorf("expected no output file error got %v", err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{Text, "Name   Channel\n-----  -------\nSAT          3\nO|A\tT        4\n"},
+		{CSV, "Name,Channel\nSAT,3\nO|A\tT,4\n"},
+		{TSV, "Name\tChannel\nSAT\t3\nO|A\\tT\t4\n"},
+		{NDJSON, "{\"Name\":\"SAT\",\"Channel\":\"3\"}\n{\"Name\":\"O|A\\tT\",\"Channel\":\"4\"}\n"},
+		{Markdown, "| Name | Channel |\n| --- | --- |\n| SAT | 3 |\n| O\\|A\tT | 4 |\n"},
+		{JSON, "[\n  {\n    \"Name\": \"SAT\",\n    \"Channel\": \"3\"\n  },\n  {\n    \"Name\": \"O|A\\tT\",\n    \"Channel\": \"4\"\n  }\n]\n"},
+		{YAML, "- Name: SAT\n  Channel: \"3\"\n- Name: \"O|A\\tT\"\n  Channel: \"4\"\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Write(&b, test.format, testTable); err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("expected %q got %q", test.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestWriteTextNumbers(t *testing.T) {
+	var b bytes.Buffer
+	tbl := &Table{
+		Header: []string{"Count", "Code", "Name"},
+		Rows:   [][]string{{"12", "007", "SAT"}, {"", "3", "RAT"}, {"1.5", "10", "MAT"}},
+	}
+	if err := Write(&b, Text, tbl); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	expected := "Count  Code  Name\n-----  ----  ----\n   12  007   SAT\n       3     RAT\n  1.5  10    MAT\n"
+	if b.String() != expected {
+		t.Errorf("expected %q got %q", expected, b.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b bytes.Buffer
+	tbl := &Table{
+		Header: []string{"Name"},
+		Rows:   [][]string{{"<script>"}},
+	}
+	if err := Write(&b, HTML, tbl); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	s := b.String()
+	if !strings.Contains(s, "<th>Name</th>") {
+		t.Error("expected the header cell")
+	}
+	if !strings.Contains(s, "<td>&lt;script&gt;</td>") {
+		t.Error("expected the escaped value")
+	}
+}
@@ -0,0 +1,166 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeText writes the table as padded columns with an underlined header.
+// The columns of numbers are right aligned.
+func writeText(w io.Writer, t *Table) error {
+
+	ws := t.Widths()
+
+	formats := make([]string, len(ws))
+	for i, n := range ws {
+		switch {
+		case t.isNumeric(i) && i == len(ws)-1:
+			formats[i] = fmt.Sprintf("%%%ds", n)
+		case t.isNumeric(i):
+			formats[i] = fmt.Sprintf("%%%ds  ", n)
+		case i == len(ws)-1:
+			// no trailing padding on the last column
+			formats[i] = "%s"
+		default:
+			formats[i] = fmt.Sprintf("%%-%ds  ", n)
+		}
+	}
+
+	printRow := func(row []string) error {
+		b := make([]byte, 0, 128)
+		for i := range ws {
+			v := ""
+			if i < len(row) {
+				v = row[i]
+			}
+			b = fmt.Appendf(b, formats[i], v)
+		}
+		b = append(b, '\n')
+		_, err := w.Write(b)
+		return err
+	}
+
+	if err := printRow(t.Header); err != nil {
+		return err
+	}
+	line := make([]string, len(ws))
+	for i, n := range ws {
+		line[i] = strings.Repeat("-", n)
+	}
+	if err := printRow(line); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := printRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isNumeric tests if the values of the column are all numbers, the empty
+// values are skipped. The numbers are the values written as numbers to
+// xlsx.
+func (t *Table) isNumeric(col int) bool {
+	numbers := 0
+	for _, row := range t.Rows {
+		if col >= len(row) || row[col] == "" {
+			continue
+		}
+		switch cellValue(row[col]).(type) {
+		case int64, float64:
+			numbers++
+		default:
+			return false
+		}
+	}
+	return numbers > 0
+}
+
+// writeCSV writes the table as comma separated values
+func writeCSV(w io.Writer, t *Table) error {
+	csvW := csv.NewWriter(w)
+	if err := csvW.Write(t.Header); err != nil {
+		return fmt.Errorf("error writing record to csv: %w", err)
+	}
+	for _, row := range t.Rows {
+		if err := csvW.Write(row); err != nil {
+			return fmt.Errorf("error writing record to csv: %w", err)
+		}
+	}
+	csvW.Flush()
+	return csvW.Error()
+}
+
+// tsvEscape escapes the characters that would break the tsv layout
+var tsvEscape = strings.NewReplacer(
+	`\`, `\\`,
+	"\t", `\t`,
+	"\r", `\r`,
+	"\n", `\n`,
+)
+
+// writeTSV writes the table as tab separated values. Tabs, new lines
+// and backslashes in the values are escaped with a backslash.
+func writeTSV(w io.Writer, t *Table) error {
+	printRow := func(row []string) error {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = tsvEscape.Replace(v)
+		}
+		_, err := fmt.Fprintln(w, strings.Join(cells, "\t"))
+		return err
+	}
+	if err := printRow(t.Header); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := printRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mdEscape escapes the characters that would break a markdown table
+var mdEscape = strings.NewReplacer(
+	`|`, `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// writeMarkdown writes the table as a markdown table
+func writeMarkdown(w io.Writer, t *Table) error {
+	printRow := func(row []string) error {
+		b := make([]byte, 0, 128)
+		b = append(b, '|')
+		for i := range t.Header {
+			v := ""
+			if i < len(row) {
+				v = mdEscape.Replace(row[i])
+			}
+			b = fmt.Appendf(b, " %s |", v)
+		}
+		b = append(b, '\n')
+		_, err := w.Write(b)
+		return err
+	}
+	if err := printRow(t.Header); err != nil {
+		return err
+	}
+	line := make([]string, len(t.Header))
+	for i := range line {
+		line[i] = "---"
+	}
+	if err := printRow(line); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := printRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tpacheco/dmptool/dmp"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// maxColWidth limits the width of the columns with long values
+	maxColWidth = 80
+
+	// maxSheetName is the max length excel allows for sheet names
+	maxSheetName = 31
+
+	resultsSheet = "Results"
+	summarySheet = "Summary"
+	tableStyle   = "TableStyleMedium2"
+)
+
+// sheetNameEscape replaces the characters excel does not allow in sheet names
+var sheetNameEscape = strings.NewReplacer(
+	`\`, "_",
+	"/", "_",
+	"?", "_",
+	"*", "_",
+	"[", "(",
+	"]", ")",
+	":", "_",
+)
+
+// sheet is the rows written to a single worksheet
+type sheet struct {
+	name string
+	key  string
+	rows [][]string
+}
+
+// writeXlsx writes the table as an excel workbook. Each sheet has the
+// rows as an excel table with filters and a frozen header row.
+//
+// If the table has a SheetBy column the rows are split into a sheet for
+// each distinct value of the column, and a summary sheet with the count
+// of rows in each sheet is added first.
+func writeXlsx(w io.Writer, t *Table) error {
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheets, err := splitSheets(t)
+	if err != nil {
+		return err
+	}
+
+	first := "Sheet1"
+	if t.SheetBy != "" {
+		if err := f.SetSheetName(first, summarySheet); err != nil {
+			return err
+		}
+		if err := writeSummary(f, t.SheetBy, sheets); err != nil {
+			return err
+		}
+		first = ""
+	}
+
+	for i, s := range sheets {
+		if first != "" {
+			if err := f.SetSheetName(first, s.name); err != nil {
+				return err
+			}
+			first = ""
+		} else if _, err := f.NewSheet(s.name); err != nil {
+			return err
+		}
+		if err := writeSheet(f, s.name, fmt.Sprintf("Table%d", i+1), t.Header, s.rows); err != nil {
+			return err
+		}
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// splitSheets splits the rows into the sheets by the SheetBy column
+func splitSheets(t *Table) ([]*sheet, error) {
+
+	if t.SheetBy == "" {
+		return []*sheet{{name: resultsSheet, rows: t.Rows}}, nil
+	}
+
+	col := t.Column(t.SheetBy)
+	if col < 0 {
+		return nil, fmt.Errorf("sheet column %q is not in the results", t.SheetBy)
+	}
+
+	sheets := make([]*sheet, 0)
+	byKey := make(map[string]*sheet)
+	used := map[string]bool{strings.ToLower(summarySheet): true}
+
+	for _, row := range t.Rows {
+		key := ""
+		if col < len(row) {
+			key = row[col]
+		}
+		s, ok := byKey[key]
+		if !ok {
+			s = &sheet{
+				name: sheetName(key, used),
+				key:  key,
+			}
+			byKey[key] = s
+			sheets = append(sheets, s)
+		}
+		s.rows = append(s.rows, row)
+	}
+	if len(sheets) == 0 {
+		sheets = append(sheets, &sheet{name: resultsSheet})
+	}
+	return sheets, nil
+}
+
+// sheetName returns a valid unique sheet name for the value
+func sheetName(value string, used map[string]bool) string {
+	name := strings.TrimSpace(sheetNameEscape.Replace(value))
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "(none)"
+	}
+	// the length is counted in characters, not bytes
+	runes := []rune(name)
+	if len(runes) > maxSheetName {
+		// keep the end of the name, paths are more unique at the end
+		runes = runes[len(runes)-maxSheetName:]
+		name = string(runes)
+	}
+	unique := name
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		sfx := fmt.Sprintf(" (%d)", i)
+		unique = string(runes[:min(len(runes), maxSheetName-len(sfx))]) + sfx
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+// writeSummary writes the count of rows for each sheet
+func writeSummary(f *excelize.File, column string, sheets []*sheet) error {
+	header := []string{column, "Sheet", "Count"}
+	rows := make([][]string, len(sheets))
+	for i, s := range sheets {
+		rows[i] = []string{s.key, s.name, strconv.Itoa(len(s.rows))}
+	}
+	if err := writeSheet(f, summarySheet, "Summary", header, rows); err != nil {
+		return err
+	}
+	for i, s := range sheets {
+		cell, err := excelize.CoordinatesToCellName(2, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellHyperLink(summarySheet, cell, fmt.Sprintf("'%s'!A1", s.name), "Location"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSheet writes the header and rows to the sheet as an excel table
+func writeSheet(f *excelize.File, name string, table string, header []string, rows [][]string) error {
+
+	ws := (&Table{Header: header, Rows: rows}).Widths()
+	for i, n := range ws {
+		col, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetColWidth(name, col, col, float64(min(n, maxColWidth)+2)); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetSheetRow(name, "A1", &header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		values := cellValues(row)
+		if err := f.SetSheetRow(name, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetPanes(name, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	if len(header) == 0 {
+		return nil
+	}
+	last, err := excelize.CoordinatesToCellName(len(header), len(rows)+1)
+	if err != nil {
+		return err
+	}
+	rng := "A1:" + last
+	if len(rows) == 0 || !uniqueHeader(header) {
+		// excel tables need data rows and unique column names
+		return f.AutoFilter(name, rng, nil)
+	}
+	showStripes := true
+	return f.AddTable(name, &excelize.Table{
+		Range:          rng,
+		Name:           table,
+		StyleName:      tableStyle,
+		ShowRowStripes: &showStripes,
+	})
+}
+
+// uniqueHeader tests if the column names are unique
+func uniqueHeader(header []string) bool {
+	seen := make(map[string]bool, len(header))
+	for _, h := range header {
+		k := strings.ToLower(h)
+		if h == "" || seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+
+// cellValues converts the row values to typed cell values so numbers
+// and dump timestamps are stored as numbers and dates.
+func cellValues(row []string) []any {
+	values := make([]any, len(row))
+	for i, s := range row {
+		values[i] = cellValue(s)
+	}
+	return values
+}
+
+// cellValue returns the typed value for the string. Numbers with leading
+// zeros are kept as text so codes like 007 are not changed.
+func cellValue(s string) any {
+	v := strings.TrimSpace(s)
+	if v == "" || v != s {
+		return s
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if hasLeadingZero(v) {
+			return s
+		}
+		return n
+	}
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		if hasLeadingZero(v) || strings.ContainsAny(v, "xXpPnN_") {
+			// keep hex, inf, nan and underscored values as text
+			return s
+		}
+		return n
+	}
+	if t, err := dmp.ParseTime(v); err == nil {
+		return t
+	}
+	return s
+}
+
+// hasLeadingZero tests for a number with a leading zero like 007
+func hasLeadingZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 1 && s[0] == '0' && s[1] != '.'
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCellValue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"12", int64(12)},
+		{"-3", int64(-3)},
+		{"1.5", 1.5},
+		{"0.25", 0.25},
+		{"007", "007"},
+		{"0x1F", "0x1F"},
+		{"NaN", "NaN"},
+		{" 12", " 12"},
+		{"Voltage", "Voltage"},
+		{"", ""},
+		{"1/2/2024 3:04:05 PM", time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result := cellValue(test.input)
+			if tm, ok := test.expected.(time.Time); ok {
+				if rt, ok := result.(time.Time); !ok || !rt.Equal(tm) {
+					t.Errorf("expected %v got %v", test.expected, result)
+				}
+				return
+			}
+			if result != test.expected {
+				t.Errorf("expected %#v got %#v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	used := map[string]bool{"summary": true}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`NUSite\Ctrl1`, "NUSite_Ctrl1"},
+		{"", "(none)"},
+		{"Summary", "Summary (2)"},
+		{"InfinityInput", "InfinityInput"},
+		{"infinityinput", "infinityinput (2)"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", "FGHIJKLMNOPQRSTUVWXYZ0123456789"},
+		{"Bâtiment_Étage_Température_Soufflée", "ment_Étage_Température_Soufflée"},
+		{"Bâtiment_Étage_Température_Soufflée", "ment_Étage_Température_Souf (2)"},
+	}
+	for _, test := range tests {
+		result := sheetName(test.input, used)
+		if result != test.expected {
+			t.Errorf("expected %q got %q", test.expected, result)
+		}
+		if !utf8.ValidString(result) || utf8.RuneCountInString(result) > maxSheetName {
+			t.Errorf("expected a valid name of at most %d characters got %q", maxSheetName, result)
+		}
+	}
+}
+
+func TestWriteXlsx(t *testing.T) {
+
+	header := make([]string, 30)
+	for i := range header {
+		header[i] = "Col" + string(rune('A'+i%26)) + string(rune('0'+i/26))
+	}
+	header[0] = "Type"
+	rows := [][]string{
+		append([]string{"InfinityInput"}, make([]string, 29)...),
+		append([]string{"InfinityOutput"}, make([]string, 29)...),
+		append([]string{"InfinityInput"}, make([]string, 29)...),
+	}
+	rows[0][29] = "42"
+
+	var b bytes.Buffer
+	tbl := &Table{Header: header, Rows: rows, SheetBy: "type"}
+	if err := Write(&b, XLSX, tbl); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	f, err := excelize.OpenReader(&b)
+	if err != nil {
+		t.Fatalf("could not read workbook %s", err)
+	}
+	defer f.Close()
+
+	expected := []string{"Summary", "InfinityInput", "InfinityOutput"}
+	if sheets := f.GetSheetList(); !slices.Equal(sheets, expected) {
+		t.Fatalf("expected sheets %v got %v", expected, sheets)
+	}
+
+	if v, _ := f.GetCellValue("InfinityInput", "AD1"); v != header[29] {
+		t.Errorf("expected header %q in column AD got %q", header[29], v)
+	}
+	if v, _ := f.GetCellValue("InfinityInput", "AD2"); v != "42" {
+		t.Errorf("expected 42 in AD2 got %q", v)
+	}
+	if v, _ := f.GetCellValue("Summary", "C2"); v != "2" {
+		t.Errorf("expected a count of 2 got %q", v)
+	}
+	if tables, _ := f.GetTables("InfinityInput"); len(tables) != 1 {
+		t.Errorf("expected a table on the sheet got %d", len(tables))
+	}
+}
@@ -0,0 +1,46 @@
+package pe
+
+// Writes returns the index of the Ident tokens that are written by the
+// code. A name is written when it is assigned at the start of a statement,
+// Name = value, or is the target of Set or Turn On and Turn Off. The
+// statements start at the start of the line and after Then and Else.
+func Writes(tks []Token) []int {
+	writes := make([]int, 0)
+	start := true
+	for i := 0; i < len(tks); i++ {
+		tk := tks[i]
+		if !start {
+			start = tk.Kind == TokNewline || tk.Is("then") || tk.Is("else")
+			continue
+		}
+		start = tk.Kind == TokNewline || tk.Is("then") || tk.Is("else")
+
+		switch {
+		case tk.Kind == TokIdent:
+			if i+1 < len(tks) && tks[i+1].Kind == TokOperator && tks[i+1].Text == "=" {
+				writes = append(writes, i)
+			}
+
+		case tk.Is("set"):
+			if i+1 < len(tks) && tks[i+1].Kind == TokIdent {
+				writes = append(writes, i+1)
+				i++
+			}
+
+		case tk.Is("turn"):
+			// Turn On A, B or Turn A, B Off
+			for i++; i < len(tks); i++ {
+				switch {
+				case tks[i].Kind == TokIdent:
+					writes = append(writes, i)
+					continue
+				case tks[i].Kind == TokComma, tks[i].Is("on"), tks[i].Is("off"):
+					continue
+				}
+				i--
+				break
+			}
+		}
+	}
+	return writes
+}
@@ -0,0 +1,39 @@
+package pe
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrites(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"assign", "Site\\Ctrl2\\X = SAT + 1", []string{`Site\Ctrl2\X`}},
+		{"compare", "If SAT = 1 Then Fan = 0", []string{"Fan"}},
+		{"else", "If A > B Then C = 1 Else D = 2", []string{"C", "D"}},
+		{"set", "Set Site\\Ctrl2\\X = 3", []string{`Site\Ctrl2\X`}},
+		{"set to", "Set X to SAT", []string{"X"}},
+		{"turn on", "Turn On Fan1, Fan2", []string{"Fan1", "Fan2"}},
+		{"turn off after", "Turn Fan Off", []string{"Fan"}},
+		{"then turn", "If OAT < 40 Then Turn On Pump", []string{"Pump"}},
+		{"reads", "Goto Start\nReturn (X * 2)", []string{}},
+		{"lines", "A = B\nC = D 'E = F", []string{"A", "C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tks := Scan(tt.input)
+			got := make([]string, 0)
+			for _, i := range Writes(tks) {
+				got = append(got, tks[i].Text)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v got %v", tt.expected, got)
+			}
+		})
+	}
+}
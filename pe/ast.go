@@ -0,0 +1,387 @@
+package pe
+
+import "fmt"
+
+// Pos is a position in the code. Line and Col are 1 based.
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// Node is a node of the syntax tree
+type Node interface {
+	Position() Pos
+}
+
+// Stmt is a statement node
+type Stmt interface {
+	Node
+	stmt()
+}
+
+// Expr is an expression node
+type Expr interface {
+	Node
+	expr()
+}
+
+// File is the parsed code of a program or function
+type File struct {
+	Stmts    []Stmt
+	Comments []*Comment
+}
+
+func (f *File) Position() Pos { return Pos{Line: 1, Col: 1} }
+
+// Comment is a comment from a ' to the end of the line
+type Comment struct {
+	Pos
+	Text string
+}
+
+func (c *Comment) Position() Pos { return c.Pos }
+
+// Statements
+
+type (
+	// Label is a line label, Line Name
+	Label struct {
+		Pos
+		Name *Ident
+	}
+
+	// Goto jumps to a line label, Goto Name
+	Goto struct {
+		Pos
+		Label *Ident
+	}
+
+	// Declare declares local variables, Numeric A, B[10]. Sizes has the
+	// size of each array of Names and nil for the other names.
+	Declare struct {
+		Pos
+		Kind  string
+		Names []*Ident
+		Sizes []Expr
+	}
+
+	// ArgDecl declares a function argument, Arg 1 Name
+	ArgDecl struct {
+		Pos
+		Index *Number
+		Name  *Ident
+	}
+
+	// Assign sets a value, Name = Value or Set Name = Value. Index is the
+	// index of an array element, Name[Index] = Value, or nil.
+	Assign struct {
+		Pos
+		Set    bool
+		Target *Ident
+		Index  Expr
+		Value  Expr
+	}
+
+	// Turn turns points on or off, Turn On Name
+	Turn struct {
+		Pos
+		On      bool
+		Targets []*Ident
+	}
+
+	// If is a single line If or an If block ended by EndIf
+	If struct {
+		Pos
+		Cond  Expr
+		Then  []Stmt
+		Else  []Stmt
+		Block bool
+	}
+
+	// Select is a Select Case block ended by EndSelect
+	Select struct {
+		Pos
+		Value Expr
+		Cases []*Case
+	}
+
+	// Case is a case of a Select, Values is empty for Case Else
+	Case struct {
+		Pos
+		Values []Expr
+		Body   []Stmt
+	}
+
+	// For is a For loop ended by Next
+	For struct {
+		Pos
+		Var  *Ident
+		From Expr
+		To   Expr
+		Step Expr
+		Body []Stmt
+	}
+
+	// While is a While loop ended by EndWhile
+	While struct {
+		Pos
+		Cond Expr
+		Body []Stmt
+	}
+
+	// Repeat is a Repeat loop ended by Until
+	Repeat struct {
+		Pos
+		Body  []Stmt
+		Until Expr
+	}
+
+	// Return returns from a function, with an optional value
+	Return struct {
+		Pos
+		Value Expr
+	}
+
+	// Command is a keyword statement with arguments, like Stop or Print
+	Command struct {
+		Pos
+		Keyword string
+		Args    []Expr
+	}
+
+	// ExprStmt is an expression used as a statement, like a function call
+	ExprStmt struct {
+		Pos
+		X Expr
+	}
+
+	// BadStmt is a statement that could not be parsed
+	BadStmt struct {
+		Pos
+		Text string
+	}
+)
+
+func (s *Label) Position() Pos    { return s.Pos }
+func (s *Goto) Position() Pos     { return s.Pos }
+func (s *Declare) Position() Pos  { return s.Pos }
+func (s *ArgDecl) Position() Pos  { return s.Pos }
+func (s *Assign) Position() Pos   { return s.Pos }
+func (s *Turn) Position() Pos     { return s.Pos }
+func (s *If) Position() Pos       { return s.Pos }
+func (s *Select) Position() Pos   { return s.Pos }
+func (s *Case) Position() Pos     { return s.Pos }
+func (s *For) Position() Pos      { return s.Pos }
+func (s *While) Position() Pos    { return s.Pos }
+func (s *Repeat) Position() Pos   { return s.Pos }
+func (s *Return) Position() Pos   { return s.Pos }
+func (s *Command) Position() Pos  { return s.Pos }
+func (s *ExprStmt) Position() Pos { return s.Pos }
+func (s *BadStmt) Position() Pos  { return s.Pos }
+
+func (*Label) stmt()    {}
+func (*Goto) stmt()     {}
+func (*Declare) stmt()  {}
+func (*ArgDecl) stmt()  {}
+func (*Assign) stmt()   {}
+func (*Turn) stmt()     {}
+func (*If) stmt()       {}
+func (*Select) stmt()   {}
+func (*Case) stmt()     {}
+func (*For) stmt()      {}
+func (*While) stmt()    {}
+func (*Repeat) stmt()   {}
+func (*Return) stmt()   {}
+func (*Command) stmt()  {}
+func (*ExprStmt) stmt() {}
+func (*BadStmt) stmt()  {}
+
+// Expressions
+
+type (
+	// Ident is a name or path, like SAT, SAT.Value or Site\Ctrl1\SAT
+	Ident struct {
+		Pos
+		Name string
+	}
+
+	// Number is a number literal
+	Number struct {
+		Pos
+		Value string
+	}
+
+	// Time is a time of day literal, like 7:30
+	Time struct {
+		Pos
+		Value string
+	}
+
+	// String is a quoted string literal, Value is without the quotes
+	String struct {
+		Pos
+		Value string
+	}
+
+	// Constant is a keyword value like On, Off, True or False
+	Constant struct {
+		Pos
+		Name string
+	}
+
+	// Unary is a unary operation, -X or Not X
+	Unary struct {
+		Pos
+		Op string
+		X  Expr
+	}
+
+	// Binary is a binary operation, X + Y
+	Binary struct {
+		Pos
+		Op string
+		X  Expr
+		Y  Expr
+	}
+
+	// Call is a function call, Name(Args)
+	Call struct {
+		Pos
+		Func *Ident
+		Args []Expr
+	}
+
+	// Index is an element of an array, X[Index]
+	Index struct {
+		Pos
+		X     *Ident
+		Index Expr
+	}
+
+	// Paren is an expression in parentheses
+	Paren struct {
+		Pos
+		X Expr
+	}
+)
+
+func (x *Ident) Position() Pos    { return x.Pos }
+func (x *Number) Position() Pos   { return x.Pos }
+func (x *Time) Position() Pos     { return x.Pos }
+func (x *String) Position() Pos   { return x.Pos }
+func (x *Constant) Position() Pos { return x.Pos }
+func (x *Unary) Position() Pos    { return x.Pos }
+func (x *Binary) Position() Pos   { return x.Pos }
+func (x *Call) Position() Pos     { return x.Pos }
+func (x *Index) Position() Pos    { return x.Pos }
+func (x *Paren) Position() Pos    { return x.Pos }
+
+func (*Ident) expr()    {}
+func (*Number) expr()   {}
+func (*Time) expr()     {}
+func (*String) expr()   {}
+func (*Constant) expr() {}
+func (*Unary) expr()    {}
+func (*Binary) expr()   {}
+func (*Call) expr()     {}
+func (*Index) expr()    {}
+func (*Paren) expr()    {}
+
+// Inspect walks the tree in depth first order, calling f for each node.
+// If f returns false the children of the node are skipped.
+func Inspect(n Node, f func(Node) bool) {
+	if n == nil || !f(n) {
+		return
+	}
+	stmts := func(list []Stmt) {
+		for _, s := range list {
+			Inspect(s, f)
+		}
+	}
+	exprs := func(list []Expr) {
+		for _, x := range list {
+			Inspect(x, f)
+		}
+	}
+	switch n := n.(type) {
+	case *File:
+		stmts(n.Stmts)
+	case *Label:
+		Inspect(n.Name, f)
+	case *Goto:
+		Inspect(n.Label, f)
+	case *Declare:
+		for i, id := range n.Names {
+			Inspect(id, f)
+			if i < len(n.Sizes) {
+				inspectExpr(n.Sizes[i], f)
+			}
+		}
+	case *ArgDecl:
+		Inspect(n.Index, f)
+		Inspect(n.Name, f)
+	case *Assign:
+		Inspect(n.Target, f)
+		inspectExpr(n.Index, f)
+		inspectExpr(n.Value, f)
+	case *Turn:
+		for _, id := range n.Targets {
+			Inspect(id, f)
+		}
+	case *If:
+		inspectExpr(n.Cond, f)
+		stmts(n.Then)
+		stmts(n.Else)
+	case *Select:
+		inspectExpr(n.Value, f)
+		for _, c := range n.Cases {
+			Inspect(c, f)
+		}
+	case *Case:
+		exprs(n.Values)
+		stmts(n.Body)
+	case *For:
+		Inspect(n.Var, f)
+		inspectExpr(n.From, f)
+		inspectExpr(n.To, f)
+		inspectExpr(n.Step, f)
+		stmts(n.Body)
+	case *While:
+		inspectExpr(n.Cond, f)
+		stmts(n.Body)
+	case *Repeat:
+		stmts(n.Body)
+		inspectExpr(n.Until, f)
+	case *Return:
+		inspectExpr(n.Value, f)
+	case *Command:
+		exprs(n.Args)
+	case *ExprStmt:
+		inspectExpr(n.X, f)
+	case *Unary:
+		inspectExpr(n.X, f)
+	case *Binary:
+		inspectExpr(n.X, f)
+		inspectExpr(n.Y, f)
+	case *Call:
+		Inspect(n.Func, f)
+		exprs(n.Args)
+	case *Index:
+		Inspect(n.X, f)
+		inspectExpr(n.Index, f)
+	case *Paren:
+		inspectExpr(n.X, f)
+	}
+}
+
+// inspectExpr inspects an optional expression, a nil Expr interface
+// and a typed nil are both skipped.
+func inspectExpr(x Expr, f func(Node) bool) {
+	if x != nil {
+		Inspect(x, f)
+	}
+}
@@ -0,0 +1,215 @@
+package pe
+
+import (
+	"strings"
+)
+
+// indent is the indent of each block level
+const indent = "  "
+
+// keywordCase is the case of the keywords made of two words, all others
+// are capitalized.
+var keywordCase = map[string]string{
+	"datetime":  "DateTime",
+	"endif":     "EndIf",
+	"endselect": "EndSelect",
+	"endwhile":  "EndWhile",
+}
+
+func keywordText(s string) string {
+	lc := strings.ToLower(s)
+	if kw, ok := keywordCase[lc]; ok {
+		return kw
+	}
+	return strings.ToUpper(lc[:1]) + lc[1:]
+}
+
+// blocks that are opened by a line and closed by the end keyword
+const (
+	blockIf     = "endif"
+	blockSelect = "endselect"
+	blockCase   = "case"
+	blockFor    = "next"
+	blockWhile  = "endwhile"
+	blockRepeat = "until"
+)
+
+// formatter keeps the open blocks while the lines are formatted
+type formatter struct {
+	blocks []string
+	// base is the level of the statements, 1 after the first line label
+	base int
+}
+
+func (f *formatter) top() string {
+	if len(f.blocks) == 0 {
+		return ""
+	}
+	return f.blocks[len(f.blocks)-1]
+}
+
+func (f *formatter) push(b string) {
+	f.blocks = append(f.blocks, b)
+}
+
+// pop closes the block if it is open
+func (f *formatter) pop(b string) {
+	if f.top() == b {
+		f.blocks = f.blocks[:len(f.blocks)-1]
+	}
+}
+
+// level returns the indent level of the line and updates the open blocks
+func (f *formatter) level(tks []Token) int {
+	first := tks[0]
+	last := tks[len(tks)-1]
+	if last.Kind == TokComment {
+		if len(tks) == 1 {
+			return f.base + len(f.blocks)
+		}
+		last = tks[len(tks)-2]
+	}
+
+	switch {
+	case first.Is("line"):
+		f.base = 1
+		return 0
+
+	case first.Is("else"):
+		f.pop(blockIf)
+		n := f.base + len(f.blocks)
+		f.push(blockIf)
+		return n
+
+	case first.Is("endif"), first.Is("endwhile"), first.Is("next"), first.Is("until"):
+		f.pop(strings.ToLower(first.Text))
+		return f.base + len(f.blocks)
+
+	case first.Is("case"):
+		f.pop(blockCase)
+		n := f.base + len(f.blocks)
+		f.push(blockCase)
+		return n
+
+	case first.Is("endselect"):
+		f.pop(blockCase)
+		f.pop(blockSelect)
+		return f.base + len(f.blocks)
+	}
+
+	n := f.base + len(f.blocks)
+	switch {
+	case first.Is("if") && last.Is("then"):
+		f.push(blockIf)
+	case first.Is("select"):
+		f.push(blockSelect)
+	case first.Is("for"):
+		f.push(blockFor)
+	case first.Is("while"):
+		f.push(blockWhile)
+	case first.Is("repeat"):
+		f.push(blockRepeat)
+	}
+	return n
+}
+
+// isOpen tests if the token opens a group, a ( or the [ of an index
+func isOpen(tk Token) bool {
+	return tk.Kind == TokParenLeft || tk.Kind == TokBracketLeft
+}
+
+// isClose tests if the token closes a group, a ) or the ] of an index
+func isClose(tk Token) bool {
+	return tk.Kind == TokParenRight || tk.Kind == TokBracketRight
+}
+
+// isTime tests if the token at i is the : of a time written with spaces
+// between the numbers, 7 : 30
+func isTime(tks []Token, i int) bool {
+	return tks[i].Kind == TokOperator && tks[i].Text == ":" &&
+		i > 0 && tks[i-1].Kind == TokNumber &&
+		i+1 < len(tks) && tks[i+1].Kind == TokNumber
+}
+
+// isValue tests if the token ends a value, an operator after it is binary
+func isValue(tk Token) bool {
+	if isClose(tk) {
+		return true
+	}
+	switch tk.Kind {
+	case TokIdent, TokNumber, TokTime, TokString:
+		return true
+	case TokKeyword:
+		return tk.Is("on") || tk.Is("off") || tk.Is("true") || tk.Is("false")
+	}
+	return false
+}
+
+// space tests if a space is written between the tokens at i-1 and i
+func space(tks []Token, i int, unary bool) bool {
+	prev, tk := tks[i-1], tks[i]
+	switch {
+	case isOpen(prev), unary:
+		return false
+	case isTime(tks, i), isTime(tks, i-1):
+		return false
+	case tk.Kind == TokComma, isClose(tk):
+		return false
+	case isOpen(tk):
+		return prev.Kind != TokIdent
+	}
+	return true
+}
+
+// formatLine writes the tokens of a line with the keywords in case and
+// single spaces between the tokens.
+func formatLine(b *strings.Builder, tks []Token) {
+	unary := false
+	for i, tk := range tks {
+		if i > 0 && space(tks, i, unary) {
+			b.WriteByte(' ')
+		}
+		unary = false
+		switch {
+		case tk.Kind == TokKeyword:
+			b.WriteString(keywordText(tk.Text))
+		case tk.Kind == TokOperator && (tk.Text == "-" || tk.Text == "+"):
+			unary = i == 0 || !isValue(tks[i-1])
+			b.WriteString(tk.Text)
+		default:
+			b.WriteString(tk.Text)
+		}
+	}
+}
+
+// Format returns the code with the keywords capitalized, the lines of
+// the If, Select, For, While and Repeat blocks indented and single spaces
+// around the operators. Statements are indented under the line labels.
+// Comments, blank lines and the line endings are kept.
+func Format(src string) string {
+	eol := "\n"
+	if strings.Contains(src, "\r\n") {
+		eol = "\r\n"
+	}
+
+	f := &formatter{}
+	b := &strings.Builder{}
+	line := make([]Token, 0)
+	flush := func() {
+		if len(line) > 0 {
+			b.WriteString(strings.Repeat(indent, f.level(line)))
+			formatLine(b, line)
+		}
+		line = line[:0]
+	}
+	for _, tk := range Scan(src) {
+		if tk.Kind == TokNewline {
+			flush()
+			b.WriteString(eol)
+			continue
+		}
+		line = append(line, tk)
+	}
+	flush()
+	return b.String()
+}
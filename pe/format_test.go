@@ -0,0 +1,39 @@
+package pe
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"keywords", "numeric a,b\ndatetime d\nif a is off then goto start", "Numeric a, b\nDateTime d\nIf a Is Off Then Goto start"},
+		{"operators", "x=a+b*-2\ny = max( a,b )-(c)", "x = a + b * -2\ny = max(a, b) - (c)"},
+		{"unary", "x = -a\nIf x > -1 Then y = - (a - -b)", "x = -a\nIf x > -1 Then y = -(a - -b)"},
+		{"labels", "line Start\nx = 1\nLine Next1\n    goto Start", "Line Start\n  x = 1\nLine Next1\n  Goto Start"},
+		{"if block", "If a Then\nx = 1\nelse if b then\nx = 2\nelse\nx = 3\nendif", "If a Then\n  x = 1\nElse If b Then\n  x = 2\nElse\n  x = 3\nEndIf"},
+		{"select", "select case m\ncase 1\nx = 1\ncase else\nx = 0\nendselect", "Select Case m\n  Case 1\n    x = 1\n  Case Else\n    x = 0\nEndSelect"},
+		{"loops", "for i = 1 to 3\nwhile x\nrepeat\nx = 1\nuntil y\nendwhile\nnext i", "For i = 1 To 3\n  While x\n    Repeat\n      x = 1\n    Until y\n  EndWhile\nNext i"},
+		{"comments", "x=1   'it's   kept\n   ' own line\nIf a Then 'block\nx=2\nEndIf", "x = 1 'it's   kept\n' own line\nIf a Then 'block\n  x = 2\nEndIf"},
+		{"strings", `Print "a+b",x`, `Print "a+b", x`},
+		{"blank lines", "x = 1\n\n\ny = 2\n", "x = 1\n\n\ny = 2\n"},
+		{"crlf", "x=1\r\ny=2  \r\n", "x = 1\r\ny = 2\r\n"},
+		{"arrays", "Numeric X[10]\nx[ i+1 ]=y [2]-1", "Numeric X[10]\nx[i + 1] = y[2] - 1"},
+		{"times", "If TOD>7:30 And TOD<17 : 45 Then x=1", "If TOD > 7:30 And TOD < 17:45 Then x = 1"},
+		{"unbalanced", "EndIf\nx = 1", "EndIf\nx = 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.input)
+			if got != tt.expected {
+				t.Errorf("expected\n%q\ngot\n%q", tt.expected, got)
+			}
+			if again := Format(got); again != got {
+				t.Errorf("expected the format to be stable, got\n%q", again)
+			}
+		})
+	}
+}
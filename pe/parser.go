@@ -0,0 +1,619 @@
+package pe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a syntax error in the code
+type Error struct {
+	Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// bailout is the panic used to abandon a statement with an error
+type bailout struct{}
+
+type parser struct {
+	tks  []Token
+	pos  int
+	errs []*Error
+	file *File
+}
+
+// Parse parses the code into a syntax tree. Statements with errors are
+// kept as BadStmt nodes and the errors are returned, so the tree has all
+// the statements of the code.
+func Parse(src string) (*File, []*Error) {
+	p := &parser{file: &File{}}
+	for _, tk := range Scan(src) {
+		if tk.Kind == TokComment {
+			p.file.Comments = append(p.file.Comments, &Comment{
+				Pos:  Pos{tk.Line, tk.Col},
+				Text: tk.Text,
+			})
+			continue
+		}
+		p.tks = append(p.tks, tk)
+	}
+	p.file.Stmts = p.block(nil)
+	for !p.atEnd() {
+		// a block end keyword without the block
+		tk := p.next()
+		p.errs = append(p.errs, &Error{Pos{tk.Line, tk.Col}, fmt.Sprintf("unexpected %s", tk.Text)})
+		p.skipLine()
+		p.file.Stmts = append(p.file.Stmts, p.block(nil)...)
+	}
+	return p.file, p.errs
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.tks)
+}
+
+// peek returns the next token, a Newline at the end of the code
+func (p *parser) peek() Token {
+	return p.peekAt(0)
+}
+
+// peekAt returns the token n tokens ahead
+func (p *parser) peekAt(n int) Token {
+	if p.pos+n >= len(p.tks) {
+		return p.eof()
+	}
+	return p.tks[p.pos+n]
+}
+
+// eof returns a Newline placed after the last token of the code
+func (p *parser) eof() Token {
+	if len(p.tks) == 0 {
+		return Token{Kind: TokNewline, Line: 1, Col: 1}
+	}
+	last := p.tks[len(p.tks)-1]
+	return Token{Kind: TokNewline, Line: last.Line, Col: last.Col + len(last.Text)}
+}
+
+func (p *parser) next() Token {
+	tk := p.peek()
+	if !p.atEnd() {
+		p.pos++
+	}
+	return tk
+}
+
+func tokenPos(tk Token) Pos {
+	return Pos{tk.Line, tk.Col}
+}
+
+// fail records the error and abandons the statement
+func (p *parser) fail(tk Token, format string, args ...any) {
+	p.errs = append(p.errs, &Error{tokenPos(tk), fmt.Sprintf(format, args...)})
+	panic(bailout{})
+}
+
+func describe(tk Token) string {
+	if tk.Kind == TokNewline {
+		return "end of line"
+	}
+	return fmt.Sprintf("%q", tk.Text)
+}
+
+func (p *parser) expectKeyword(kw string) Token {
+	tk := p.peek()
+	if !tk.Is(kw) {
+		p.fail(tk, "expected %s, found %s", kw, describe(tk))
+	}
+	return p.next()
+}
+
+func (p *parser) expectIdent() *Ident {
+	tk := p.peek()
+	if tk.Kind != TokIdent {
+		p.fail(tk, "expected a name, found %s", describe(tk))
+	}
+	p.next()
+	return &Ident{Pos: tokenPos(tk), Name: tk.Text}
+}
+
+func (p *parser) skipLine() {
+	for !p.atEnd() && p.peek().Kind != TokNewline {
+		p.next()
+	}
+}
+
+// endOfStmt tests if the statement has ended
+func (p *parser) endOfStmt() bool {
+	tk := p.peek()
+	return tk.Kind == TokNewline || tk.Is("else")
+}
+
+// block parses the statements up to the end keyword, or the end of the
+// code if end is nil. The end keyword is not consumed.
+func (p *parser) block(end func(Token) bool) []Stmt {
+	stmts := make([]Stmt, 0)
+	for {
+		for !p.atEnd() && p.peek().Kind == TokNewline {
+			p.next()
+		}
+		if p.atEnd() {
+			return stmts
+		}
+		if end != nil && end(p.peek()) {
+			return stmts
+		}
+		if isBlockEnd(p.peek()) {
+			// the end of an enclosing block or a stray end keyword
+			return stmts
+		}
+		s := p.statement()
+		stmts = append(stmts, s)
+		if _, bad := s.(*BadStmt); !bad && p.peek().Kind != TokNewline {
+			tk := p.peek()
+			p.errs = append(p.errs, &Error{tokenPos(tk), fmt.Sprintf("unexpected %s", describe(tk))})
+			p.skipLine()
+		}
+	}
+}
+
+// isBlockEnd tests for the keywords that end a block
+func isBlockEnd(tk Token) bool {
+	if tk.Kind != TokKeyword {
+		return false
+	}
+	switch strings.ToLower(tk.Text) {
+	case "else", "endif", "case", "endselect", "next", "endwhile", "until":
+		return true
+	}
+	return false
+}
+
+func endAt(kws ...string) func(Token) bool {
+	return func(tk Token) bool {
+		for _, kw := range kws {
+			if tk.Is(kw) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// statement parses a statement. On an error the rest of the line is
+// skipped and a BadStmt is returned.
+func (p *parser) statement() (s Stmt) {
+	start := p.pos
+	tk := p.peek()
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			p.skipLine()
+			s = &BadStmt{Pos: tokenPos(tk), Text: p.text(start, p.pos)}
+		}
+	}()
+	return p.parseStmt()
+}
+
+// text returns the source text of the tokens
+func (p *parser) text(from int, to int) string {
+	parts := make([]string, 0, to-from)
+	for _, tk := range p.tks[from:min(to, len(p.tks))] {
+		parts = append(parts, tk.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *parser) parseStmt() Stmt {
+	tk := p.peek()
+	pos := tokenPos(tk)
+
+	if tk.Kind == TokIdent {
+		start := p.pos
+		target, index := p.target()
+		if op := p.peek(); op.Kind == TokOperator && op.Text == "=" {
+			p.next()
+			return &Assign{Pos: pos, Target: target, Index: index, Value: p.expr()}
+		}
+		p.pos = start
+		return &ExprStmt{Pos: pos, X: p.expr()}
+	}
+
+	if tk.Kind != TokKeyword {
+		p.fail(tk, "unexpected %s", describe(tk))
+	}
+
+	kw := strings.ToLower(tk.Text)
+	switch kw {
+	case "line":
+		p.next()
+		name := p.peek()
+		if name.Kind != TokIdent && name.Kind != TokNumber {
+			p.fail(name, "expected a line label, found %s", describe(name))
+		}
+		p.next()
+		return &Label{Pos: pos, Name: &Ident{Pos: tokenPos(name), Name: name.Text}}
+
+	case "goto":
+		p.next()
+		name := p.peek()
+		if name.Kind != TokIdent && name.Kind != TokNumber {
+			p.fail(name, "expected a line label, found %s", describe(name))
+		}
+		p.next()
+		return &Goto{Pos: pos, Label: &Ident{Pos: tokenPos(name), Name: name.Text}}
+
+	case "numeric", "string", "datetime", "integer":
+		p.next()
+		d := &Declare{Pos: pos, Kind: tk.Text}
+		for {
+			name, size := p.target()
+			d.Names = append(d.Names, name)
+			d.Sizes = append(d.Sizes, size)
+			if p.peek().Kind != TokComma {
+				return d
+			}
+			p.next()
+		}
+
+	case "arg":
+		p.next()
+		n := p.peek()
+		if n.Kind != TokNumber {
+			p.fail(n, "expected the argument number, found %s", describe(n))
+		}
+		p.next()
+		return &ArgDecl{Pos: pos, Index: &Number{Pos: tokenPos(n), Value: n.Text}, Name: p.expectIdent()}
+
+	case "set":
+		p.next()
+		target, index := p.target()
+		if op := p.peek(); (op.Kind == TokOperator && op.Text == "=") || op.Is("to") {
+			p.next()
+		} else {
+			p.fail(op, "expected = or To, found %s", describe(op))
+		}
+		return &Assign{Pos: pos, Set: true, Target: target, Index: index, Value: p.expr()}
+
+	case "turn":
+		p.next()
+		t := &Turn{Pos: pos}
+		state := false
+		if p.peek().Is("on") || p.peek().Is("off") {
+			t.On = p.next().Is("on")
+			state = true
+		}
+		t.Targets = append(t.Targets, p.expectIdent())
+		for p.peek().Kind == TokComma {
+			p.next()
+			t.Targets = append(t.Targets, p.expectIdent())
+		}
+		if !state {
+			if !p.peek().Is("on") && !p.peek().Is("off") {
+				p.fail(p.peek(), "expected On or Off, found %s", describe(p.peek()))
+			}
+			t.On = p.next().Is("on")
+		}
+		return t
+
+	case "if":
+		return p.parseIf()
+
+	case "select":
+		return p.parseSelect()
+
+	case "for":
+		return p.parseFor()
+
+	case "while":
+		p.next()
+		w := &While{Pos: pos, Cond: p.expr()}
+		w.Body = p.block(endAt("endwhile"))
+		p.endBlock(tk, "EndWhile")
+		return w
+
+	case "repeat":
+		p.next()
+		r := &Repeat{Pos: pos}
+		r.Body = p.block(endAt("until"))
+		p.endBlock(tk, "Until")
+		r.Until = p.expr()
+		return r
+
+	case "return":
+		p.next()
+		r := &Return{Pos: pos}
+		if !p.endOfStmt() {
+			r.Value = p.expr()
+		}
+		return r
+
+	case "stop", "print", "wait":
+		p.next()
+		c := &Command{Pos: pos, Keyword: tk.Text}
+		if !p.endOfStmt() {
+			c.Args = p.exprList()
+		}
+		return c
+	}
+
+	p.fail(tk, "unexpected %s", describe(tk))
+	return nil
+}
+
+// endBlock consumes the end keyword of the block started by the token,
+// or records an error for the missing end.
+func (p *parser) endBlock(start Token, end string) {
+	if p.peek().Is(end) {
+		p.next()
+		return
+	}
+	p.errs = append(p.errs, &Error{tokenPos(start), fmt.Sprintf("%s without %s", start.Text, end)})
+}
+
+// parseIf parses a single line If, If c Then s Else s, or an If block
+// with the Then and Else parts on the following lines ended by EndIf.
+func (p *parser) parseIf() Stmt {
+	tk := p.next()
+	s := &If{Pos: tokenPos(tk), Cond: p.expr()}
+	p.expectKeyword("then")
+
+	if p.peek().Kind != TokNewline {
+		// single line If
+		s.Then = []Stmt{p.parseStmt()}
+		if p.peek().Is("else") {
+			p.next()
+			s.Else = []Stmt{p.parseStmt()}
+		}
+		return s
+	}
+
+	s.Block = true
+	s.Then = p.block(endAt("else", "endif"))
+	if p.peek().Is("else") {
+		p.next()
+		if p.peek().Kind != TokNewline {
+			// Else If chains end with the EndIf of the last If
+			s.Else = []Stmt{p.parseStmt()}
+			if inner, ok := s.Else[0].(*If); ok && inner.Block {
+				return s
+			}
+			// a single line Else If, the Else part goes on to the EndIf
+			if tk := p.peek(); tk.Kind != TokNewline {
+				p.errs = append(p.errs, &Error{tokenPos(tk), fmt.Sprintf("unexpected %s", describe(tk))})
+				p.skipLine()
+			}
+			s.Else = append(s.Else, p.block(endAt("endif"))...)
+		} else {
+			s.Else = p.block(endAt("endif"))
+		}
+	}
+	p.endBlock(tk, "EndIf")
+	return s
+}
+
+// parseSelect parses a Select Case block ended by EndSelect
+func (p *parser) parseSelect() Stmt {
+	tk := p.next()
+	if p.peek().Is("case") {
+		p.next()
+	}
+	s := &Select{Pos: tokenPos(tk), Value: p.expr()}
+	if p.peek().Kind != TokNewline {
+		p.fail(p.peek(), "unexpected %s", describe(p.peek()))
+	}
+	if stmts := p.block(endAt("case", "endselect")); len(stmts) > 0 {
+		p.errs = append(p.errs, &Error{stmts[0].Position(), "statement before the first Case"})
+	}
+
+	for p.peek().Is("case") {
+		ct := p.next()
+		c := &Case{Pos: tokenPos(ct)}
+		if p.peek().Is("else") {
+			p.next()
+		} else {
+			c.Values = p.exprList()
+		}
+		c.Body = p.block(endAt("case", "endselect"))
+		s.Cases = append(s.Cases, c)
+	}
+	p.endBlock(tk, "EndSelect")
+	return s
+}
+
+// parseFor parses a For loop ended by Next
+func (p *parser) parseFor() Stmt {
+	tk := p.next()
+	s := &For{Pos: tokenPos(tk), Var: p.expectIdent()}
+	if op := p.peek(); op.Kind != TokOperator || op.Text != "=" {
+		p.fail(op, "expected =, found %s", describe(op))
+	}
+	p.next()
+	s.From = p.expr()
+	p.expectKeyword("to")
+	s.To = p.expr()
+	if p.peek().Is("step") {
+		p.next()
+		s.Step = p.expr()
+	}
+	s.Body = p.block(endAt("next"))
+	if p.peek().Is("next") {
+		p.next()
+		if p.peek().Kind == TokIdent {
+			p.next()
+		}
+		return s
+	}
+	p.errs = append(p.errs, &Error{tokenPos(tk), "For without Next"})
+	return s
+}
+
+// target parses a name with an optional array index, Name or Name[Index].
+// The index is nil for a name without one.
+func (p *parser) target() (*Ident, Expr) {
+	id := p.expectIdent()
+	if p.peek().Kind != TokBracketLeft {
+		return id, nil
+	}
+	return id, p.index()
+}
+
+// index parses the index of an array element, [Index]
+func (p *parser) index() Expr {
+	p.next()
+	x := p.expr()
+	if p.peek().Kind != TokBracketRight {
+		p.fail(p.peek(), "expected ], found %s", describe(p.peek()))
+	}
+	p.next()
+	return x
+}
+
+func (p *parser) exprList() []Expr {
+	list := []Expr{p.expr()}
+	for p.peek().Kind == TokComma {
+		p.next()
+		list = append(list, p.expr())
+	}
+	return list
+}
+
+// operator precedence, higher binds tighter
+func precedence(tk Token) int {
+	switch tk.Kind {
+	case TokKeyword:
+		switch strings.ToLower(tk.Text) {
+		case "or":
+			return 1
+		case "and":
+			return 2
+		case "is":
+			return 4
+		}
+	case TokOperator:
+		switch tk.Text {
+		case "=", "<>", "<", ">", "<=", ">=":
+			return 4
+		case "+", "-", "&":
+			return 5
+		case "*", "/", "%":
+			return 6
+		case "^":
+			return 7
+		}
+	}
+	return 0
+}
+
+// expr parses an expression
+func (p *parser) expr() Expr {
+	return p.binary(1)
+}
+
+func (p *parser) binary(minPrec int) Expr {
+	x := p.unary()
+	for {
+		tk := p.peek()
+		prec := precedence(tk)
+		if prec < minPrec || prec == 0 {
+			return x
+		}
+		p.next()
+		op := tk.Text
+		if tk.Is("is") {
+			op = "="
+			if p.peek().Is("not") {
+				p.next()
+				op = "<>"
+			}
+		}
+		// ^ is right associative, the others are left associative
+		next := prec + 1
+		if op == "^" {
+			next = prec
+		}
+		y := p.binary(next)
+		x = &Binary{Pos: tokenPos(tk), Op: op, X: x, Y: y}
+	}
+}
+
+func (p *parser) unary() Expr {
+	tk := p.peek()
+	switch {
+	case tk.Is("not"):
+		p.next()
+		// Not binds looser than the comparisons
+		return &Unary{Pos: tokenPos(tk), Op: tk.Text, X: p.binary(3)}
+	case tk.Kind == TokOperator && (tk.Text == "-" || tk.Text == "+"):
+		p.next()
+		return &Unary{Pos: tokenPos(tk), Op: tk.Text, X: p.unary()}
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() Expr {
+	tk := p.peek()
+	pos := tokenPos(tk)
+	switch tk.Kind {
+	case TokNumber:
+		p.next()
+		// a time with spaces around the colon, 7 : 30
+		if colon, m := p.peek(), p.peekAt(1); colon.Kind == TokOperator && colon.Text == ":" && m.Kind == TokNumber {
+			p.next()
+			p.next()
+			return &Time{Pos: pos, Value: tk.Text + ":" + m.Text}
+		}
+		return &Number{Pos: pos, Value: tk.Text}
+
+	case TokTime:
+		p.next()
+		return &Time{Pos: pos, Value: tk.Text}
+
+	case TokString:
+		p.next()
+		v := strings.TrimSuffix(strings.TrimPrefix(tk.Text, `"`), `"`)
+		return &String{Pos: pos, Value: strings.ReplaceAll(v, `""`, `"`)}
+
+	case TokIdent:
+		p.next()
+		id := &Ident{Pos: pos, Name: tk.Text}
+		if p.peek().Kind == TokBracketLeft {
+			return &Index{Pos: pos, X: id, Index: p.index()}
+		}
+		if p.peek().Kind != TokParenLeft {
+			return id
+		}
+		p.next()
+		c := &Call{Pos: pos, Func: id}
+		if p.peek().Kind != TokParenRight {
+			c.Args = p.exprList()
+		}
+		if p.peek().Kind != TokParenRight {
+			p.fail(p.peek(), "expected ), found %s", describe(p.peek()))
+		}
+		p.next()
+		return c
+
+	case TokParenLeft:
+		p.next()
+		x := p.expr()
+		if p.peek().Kind != TokParenRight {
+			p.fail(p.peek(), "expected ), found %s", describe(p.peek()))
+		}
+		p.next()
+		return &Paren{Pos: pos, X: x}
+
+	case TokKeyword:
+		switch strings.ToLower(tk.Text) {
+		case "on", "off", "true", "false":
+			p.next()
+			return &Constant{Pos: pos, Name: tk.Text}
+		}
+	}
+	p.fail(tk, "expected a value, found %s", describe(tk))
+	return nil
+}
@@ -0,0 +1,222 @@
+package pe
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dump returns the tree as a compact s-expression for the tests
+func dump(n Node) string {
+	list := func(stmts []Stmt) string {
+		parts := make([]string, len(stmts))
+		for i, s := range stmts {
+			parts[i] = dump(s)
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	}
+	exprs := func(xs []Expr) string {
+		parts := make([]string, len(xs))
+		for i, x := range xs {
+			parts[i] = dump(x)
+		}
+		return strings.Join(parts, " ")
+	}
+	switch n := n.(type) {
+	case *File:
+		return list(n.Stmts)
+	case *Label:
+		return "(line " + n.Name.Name + ")"
+	case *Goto:
+		return "(goto " + n.Label.Name + ")"
+	case *Declare:
+		names := make([]string, len(n.Names))
+		for i, id := range n.Names {
+			names[i] = id.Name
+			if i < len(n.Sizes) && n.Sizes[i] != nil {
+				names[i] += "[" + dump(n.Sizes[i]) + "]"
+			}
+		}
+		return "(" + strings.ToLower(n.Kind) + " " + strings.Join(names, " ") + ")"
+	case *ArgDecl:
+		return "(arg " + n.Index.Value + " " + n.Name.Name + ")"
+	case *Assign:
+		op := "="
+		if n.Set {
+			op = "set"
+		}
+		target := n.Target.Name
+		if n.Index != nil {
+			target += "[" + dump(n.Index) + "]"
+		}
+		return "(" + op + " " + target + " " + dump(n.Value) + ")"
+	case *Turn:
+		names := make([]string, len(n.Targets))
+		for i, id := range n.Targets {
+			names[i] = id.Name
+		}
+		return fmt.Sprintf("(turn %v %s)", n.On, strings.Join(names, " "))
+	case *If:
+		s := "(if " + dump(n.Cond) + " " + list(n.Then)
+		if n.Else != nil {
+			s += " " + list(n.Else)
+		}
+		return s + ")"
+	case *Select:
+		parts := make([]string, len(n.Cases))
+		for i, c := range n.Cases {
+			parts[i] = dump(c)
+		}
+		return "(select " + dump(n.Value) + " " + strings.Join(parts, " ") + ")"
+	case *Case:
+		if len(n.Values) == 0 {
+			return "(else " + list(n.Body) + ")"
+		}
+		return "(case " + exprs(n.Values) + " " + list(n.Body) + ")"
+	case *For:
+		s := "(for " + n.Var.Name + " " + dump(n.From) + " " + dump(n.To)
+		if n.Step != nil {
+			s += " " + dump(n.Step)
+		}
+		return s + " " + list(n.Body) + ")"
+	case *While:
+		return "(while " + dump(n.Cond) + " " + list(n.Body) + ")"
+	case *Repeat:
+		return "(repeat " + list(n.Body) + " " + dump(n.Until) + ")"
+	case *Return:
+		if n.Value == nil {
+			return "(return)"
+		}
+		return "(return " + dump(n.Value) + ")"
+	case *Command:
+		return "(" + strings.ToLower(n.Keyword) + " " + exprs(n.Args) + ")"
+	case *ExprStmt:
+		return dump(n.X)
+	case *BadStmt:
+		return "(bad " + n.Text + ")"
+	case *Ident:
+		return n.Name
+	case *Number:
+		return n.Value
+	case *Time:
+		return "(time " + n.Value + ")"
+	case *String:
+		return fmt.Sprintf("%q", n.Value)
+	case *Constant:
+		return strings.ToLower(n.Name)
+	case *Unary:
+		return "(" + strings.ToLower(n.Op) + " " + dump(n.X) + ")"
+	case *Binary:
+		return "(" + strings.ToLower(n.Op) + " " + dump(n.X) + " " + dump(n.Y) + ")"
+	case *Call:
+		return "(" + n.Func.Name + " " + exprs(n.Args) + ")"
+	case *Index:
+		return n.X.Name + "[" + dump(n.Index) + "]"
+	case *Paren:
+		return dump(n.X)
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"assign", "Tmp = SAT + 1 * 2", "[(= Tmp (+ SAT (* 1 2)))]"},
+		{"left assoc", "X = A - B - C", "[(= X (- (- A B) C))]"},
+		{"power", "X = A ^ B ^ C", "[(= X (^ A (^ B C)))]"},
+		{"logic", "If A > 1 And Not B Or C Then X = 1", "[(if (or (and (> A 1) (not B)) C) [(= X 1)])]"},
+		{"is", "If Fan Is On Then Goto Start", "[(if (= Fan on) [(goto Start)])]"},
+		{"is not", "If Fan is not Off Then Stop", "[(if (<> Fan off) [(stop )])]"},
+		{"else", "If A Then B = 1 Else B = 2", "[(if A [(= B 1)] [(= B 2)])]"},
+		{"labels", "Line Start\nGoto Start", "[(line Start) (goto Start)]"},
+		{"declare", "Numeric A, B\nString S\nDateTime D", "[(numeric A B) (string S) (datetime D)]"},
+		{"arg", "Arg 1 X\nReturn (X * 2)", "[(arg 1 X) (return (* X 2))]"},
+		{"set", `Set Site\Ctrl2\X = 3` + "\nSet Y To Z", `[(set Site\Ctrl2\X 3) (set Y Z)]`},
+		{"turn", "Turn On Fan1, Fan2\nTurn Pump Off", "[(turn true Fan1 Fan2) (turn false Pump)]"},
+		{"call", "X = Max(A, B) + Calc()\nCalc(1)", "[(= X (+ (Max A B) (Calc ))) (Calc 1)]"},
+		{"string", `Msg = "it's ""quoted"""`, `[(= Msg "it's \"quoted\"")]`},
+		{"comment", "X = 1 'it's a comment\n' only comment", "[(= X 1)]"},
+		{"block if", "If A Then\n  X = 1\nElse\n  X = 2\nEndIf", "[(if A [(= X 1)] [(= X 2)])]"},
+		{"else if", "If A Then\n  X = 1\nElse If B Then\n  X = 2\nEndIf", "[(if A [(= X 1)] [(if B [(= X 2)])])]"},
+		{"single line else if", "If A Then\n  X = 1\nElse If B Then X = 2\n  Y = 3\nEndIf\nZ = 4",
+			"[(if A [(= X 1)] [(if B [(= X 2)]) (= Y 3)]) (= Z 4)]"},
+		{"select", "Select Case Mode\nCase 1, 2\n  X = 1\nCase Else\n  X = 0\nEndSelect",
+			"[(select Mode (case 1 2 [(= X 1)]) (else [(= X 0)]))]"},
+		{"for", "For I = 1 To 10 Step 2\n  X = X + I\nNext I", "[(for I 1 10 2 [(= X (+ X I))])]"},
+		{"while", "While X < 10\n  X = X + 1\nEndWhile", "[(while (< X 10) [(= X (+ X 1))])]"},
+		{"repeat", "Repeat\n  X = X + 1\nUntil X > 10", "[(repeat [(= X (+ X 1))] (> X 10))]"},
+		{"print", `Print "X is", X`, `[(print "X is" X)]`},
+		{"index", "Numeric Arr[10], N\nB = Arr[1]\nArr[I + 1] = B * 2\nSet Arr[N] To 0",
+			"[(numeric Arr[10] N) (= B Arr[1]) (= Arr[(+ I 1)] (* B 2)) (set Arr[N] 0)]"},
+		{"time", "If Time > 7:30 And Time < 17 : 45 Then X = 1", "[(if (and (> Time (time 7:30)) (< Time (time 17:45))) [(= X 1)])]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, errs := Parse(tt.input)
+			for _, err := range errs {
+				t.Error(err)
+			}
+			if got := dump(f); got != tt.expected {
+				t.Errorf("expected %s got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		errors   []string
+	}{
+		{"bad statement", "X = 1\n= 2\nY = 3", "[(= X 1) (bad = 2) (= Y 3)]", []string{"2:1: unexpected \"=\""}},
+		{"missing value", "X = \nY = 1", "[(bad X =) (= Y 1)]", []string{"1:5: expected a value, found end of line"}},
+		{"missing endif", "If A Then\n  X = 1", "[(if A [(= X 1)])]", []string{"1:1: If without EndIf"}},
+		{"stray end", "X = 1\nEndIf\nY = 2", "[(= X 1) (= Y 2)]", []string{"2:1: unexpected EndIf"}},
+		{"trailing", "X = 1 2", "[(= X 1)]", []string{"1:7: unexpected \"2\""}},
+		{"paren", "X = (A + 1", "[(bad X = ( A + 1)]", []string{"1:11: expected ), found end of line"}},
+		{"index", "B = Arr[1", "[(bad B = Arr [ 1)]", []string{"1:10: expected ], found end of line"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, errs := Parse(tt.input)
+			if got := dump(f); got != tt.expected {
+				t.Errorf("expected %s got %s", tt.expected, got)
+			}
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("expected errors %v got %v", tt.errors, errs)
+			}
+			for i, err := range errs {
+				if err.Error() != tt.errors[i] {
+					t.Errorf("expected error %s got %s", tt.errors[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	f, _ := Parse("Numeric X\n  If X > 1 Then Turn On Fan")
+	var got []string
+	Inspect(f, func(n Node) bool {
+		if id, ok := n.(*Ident); ok {
+			got = append(got, fmt.Sprintf("%s@%s", id.Name, id.Pos))
+		}
+		return true
+	})
+	expected := "X@1:9 X@2:6 Fan@2:25"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("expected %s got %s", expected, strings.Join(got, " "))
+	}
+	if len(f.Comments) != 0 {
+		t.Errorf("expected no comments got %d", len(f.Comments))
+	}
+}
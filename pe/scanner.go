@@ -0,0 +1,236 @@
+package pe
+
+import (
+	"strings"
+)
+
+// Scan splits the code into tokens. Spaces are skipped, and the end of
+// each line is a Newline token. A ' starts a comment to the end of the
+// line, outside of strings.
+//
+// Names can be paths with \ separators and properties, like
+// Site\Ctrl1\SAT.Value, which are a single Ident token. Times of day like
+// 7:30 are a single Time token.
+func Scan(src string) []Token {
+	tks := make([]Token, 0)
+	line, start := 1, 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		tk := Token{Line: line, Col: i - start + 1}
+		n := 1
+
+		switch {
+		case c == '\n':
+			tk.Kind = TokNewline
+			tks = append(tks, tk)
+			i++
+			line, start = line+1, i
+			continue
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+
+		case c == '\'':
+			n = strings.IndexAny(src[i:], "\r\n")
+			if n < 0 {
+				n = len(src) - i
+			}
+			tk.Kind = TokComment
+
+		case c == '"':
+			n = readString(src[i:])
+			tk.Kind = TokString
+			if n < 2 || src[i+n-1] != '"' {
+				tk.Kind = TokIllegal
+			}
+
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			n = readNumber(src[i:])
+			tk.Kind = TokNumber
+			if t := readTime(src[i:]); t > 0 {
+				n = t
+				tk.Kind = TokTime
+			}
+
+		case isNameStart(c):
+			n = readName(src[i:])
+			tk.Kind = TokIdent
+			if IsKeyword(src[i : i+n]) {
+				tk.Kind = TokKeyword
+			}
+
+		case c == ',':
+			tk.Kind = TokComma
+
+		case c == '(':
+			tk.Kind = TokParenLeft
+
+		case c == ')':
+			tk.Kind = TokParenRight
+
+		case c == '[':
+			tk.Kind = TokBracketLeft
+
+		case c == ']':
+			tk.Kind = TokBracketRight
+
+		case strings.IndexByte("=<>+-*/^&:%", c) >= 0:
+			n = readOperator(src[i:])
+			tk.Kind = TokOperator
+
+		default:
+			tk.Kind = TokIllegal
+		}
+
+		tk.Text = src[i : i+n]
+		tks = append(tks, tk)
+		i += n
+	}
+	return tks
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	u := c &^ 0x20
+	return 'A' <= u && u <= 'Z' || c >= 0x80
+}
+
+func isNameStart(c byte) bool {
+	return isLetter(c) || c == '_' || c == '\\'
+}
+
+func isName(c byte) bool {
+	return isLetter(c) || isDigit(c) || c == '_' || c == '\\' || c == '.'
+}
+
+// readString reads a quoted string, a doubled quote is a quote in
+// the string. The string ends at the end of the line if not closed.
+func readString(s string) int {
+	for p := 1; p < len(s); p++ {
+		switch s[p] {
+		case '"':
+			if p+1 < len(s) && s[p+1] == '"' {
+				p++
+				continue
+			}
+			return p + 1
+		case '\r', '\n':
+			return p
+		}
+	}
+	return len(s)
+}
+
+func readNumber(s string) int {
+	p := 0
+	for p < len(s) && isDigit(s[p]) {
+		p++
+	}
+	if p < len(s) && s[p] == '.' {
+		p++
+		for p < len(s) && isDigit(s[p]) {
+			p++
+		}
+	}
+	if p+1 < len(s) && (s[p] == 'e' || s[p] == 'E') {
+		q := p + 1
+		if s[q] == '-' || s[q] == '+' {
+			q++
+		}
+		if q < len(s) && isDigit(s[q]) {
+			p = q
+			for p < len(s) && isDigit(s[p]) {
+				p++
+			}
+		}
+	}
+	return p
+}
+
+// readTime reads a time of day, hours and minutes and optional seconds
+// separated by colons. It returns 0 if the text is not a time.
+func readTime(s string) int {
+	digits := func(p int) int {
+		q := p
+		for q < len(s) && isDigit(s[q]) {
+			q++
+		}
+		return q - p
+	}
+	p := digits(0)
+	if p == 0 || p > 2 {
+		return 0
+	}
+	for part := 0; part < 2; part++ {
+		if p+1 >= len(s) || s[p] != ':' || digits(p+1) != 2 {
+			break
+		}
+		p += 3
+	}
+	if p <= 2 || (p < len(s) && (isName(s[p]) || s[p] == ':')) {
+		return 0
+	}
+	return p
+}
+
+func readName(s string) int {
+	p := 0
+	for p < len(s) && isName(s[p]) {
+		p++
+	}
+	// a trailing dot ends the statement, it is not part of the name
+	for p > 1 && s[p-1] == '.' {
+		p--
+	}
+	return p
+}
+
+func readOperator(s string) int {
+	if len(s) > 1 {
+		switch s[:2] {
+		case "<=", ">=", "<>":
+			return 2
+		}
+	}
+	return 1
+}
+
+// Locals returns the names declared in the code by statements at the
+// start of a line. These are the local
+// variables declared with Numeric, String, DateTime and Integer, the
+// function arguments declared with Arg, and the line labels. The names
+// are in lower case as names are not case sensitive.
+func Locals(tks []Token) map[string]bool {
+	locals := make(map[string]bool)
+	for i := 0; i < len(tks); i++ {
+		tk := tks[i]
+		if tk.Kind != TokKeyword || !lineStart(tks, i) {
+			continue
+		}
+		kw := strings.ToLower(tk.Text)
+		switch {
+		case kw == "line":
+			if i+1 < len(tks) && (tks[i+1].Kind == TokIdent || tks[i+1].Kind == TokNumber) {
+				locals[strings.ToLower(tks[i+1].Text)] = true
+			}
+		case declarations[kw]:
+			// the declaration is the rest of the line: a list of names
+			// and for Arg the argument number before the name
+			for i++; i < len(tks) && tks[i].Kind != TokNewline; i++ {
+				if tks[i].Kind == TokIdent {
+					locals[strings.ToLower(tks[i].Text)] = true
+				}
+			}
+		}
+	}
+	return locals
+}
+
+// lineStart tests if the token is the first token of the line
+func lineStart(tks []Token, i int) bool {
+	return i == 0 || tks[i-1].Kind == TokNewline
+}
@@ -0,0 +1,128 @@
+package pe
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/exp/maps"
+)
+
+func TestScan(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []Token
+	}{
+		{"assign", "Tmp = SAT + 1", []Token{
+			{Kind: TokIdent, Text: "Tmp", Line: 1, Col: 1},
+			{Kind: TokOperator, Text: "=", Line: 1, Col: 5},
+			{Kind: TokIdent, Text: "SAT", Line: 1, Col: 7},
+			{Kind: TokOperator, Text: "+", Line: 1, Col: 11},
+			{Kind: TokNumber, Text: "1", Line: 1, Col: 13},
+		}},
+		{"path", `X = Site\Ctrl1\SAT.Value`, []Token{
+			{Kind: TokIdent, Text: "X", Line: 1, Col: 1},
+			{Kind: TokOperator, Text: "=", Line: 1, Col: 3},
+			{Kind: TokIdent, Text: `Site\Ctrl1\SAT.Value`, Line: 1, Col: 5},
+		}},
+		{"comment", "If A <> 1 Then B = 2 'it's a comment", []Token{
+			{Kind: TokKeyword, Text: "If", Line: 1, Col: 1},
+			{Kind: TokIdent, Text: "A", Line: 1, Col: 4},
+			{Kind: TokOperator, Text: "<>", Line: 1, Col: 6},
+			{Kind: TokNumber, Text: "1", Line: 1, Col: 9},
+			{Kind: TokKeyword, Text: "Then", Line: 1, Col: 11},
+			{Kind: TokIdent, Text: "B", Line: 1, Col: 16},
+			{Kind: TokOperator, Text: "=", Line: 1, Col: 18},
+			{Kind: TokNumber, Text: "2", Line: 1, Col: 20},
+			{Kind: TokComment, Text: "'it's a comment", Line: 1, Col: 22},
+		}},
+		{"string", `Msg = "it's \SAT"`, []Token{
+			{Kind: TokIdent, Text: "Msg", Line: 1, Col: 1},
+			{Kind: TokOperator, Text: "=", Line: 1, Col: 5},
+			{Kind: TokString, Text: `"it's \SAT"`, Line: 1, Col: 7},
+		}},
+		{"unclosed string", "S = \"abc\r\nX", []Token{
+			{Kind: TokIdent, Text: "S", Line: 1, Col: 1},
+			{Kind: TokOperator, Text: "=", Line: 1, Col: 3},
+			{Kind: TokIllegal, Text: `"abc`, Line: 1, Col: 5},
+			{Kind: TokNewline, Line: 1, Col: 10},
+			{Kind: TokIdent, Text: "X", Line: 2, Col: 1},
+		}},
+		{"numbers", "F(1.5, 2e-3, .5)", []Token{
+			{Kind: TokIdent, Text: "F", Line: 1, Col: 1},
+			{Kind: TokParenLeft, Text: "(", Line: 1, Col: 2},
+			{Kind: TokNumber, Text: "1.5", Line: 1, Col: 3},
+			{Kind: TokComma, Text: ",", Line: 1, Col: 6},
+			{Kind: TokNumber, Text: "2e-3", Line: 1, Col: 8},
+			{Kind: TokComma, Text: ",", Line: 1, Col: 12},
+			{Kind: TokNumber, Text: ".5", Line: 1, Col: 14},
+			{Kind: TokParenRight, Text: ")", Line: 1, Col: 16},
+		}},
+		{"index", "B = Arr[I+1]", []Token{
+			{Kind: TokIdent, Text: "B", Line: 1, Col: 1},
+			{Kind: TokOperator, Text: "=", Line: 1, Col: 3},
+			{Kind: TokIdent, Text: "Arr", Line: 1, Col: 5},
+			{Kind: TokBracketLeft, Text: "[", Line: 1, Col: 8},
+			{Kind: TokIdent, Text: "I", Line: 1, Col: 9},
+			{Kind: TokOperator, Text: "+", Line: 1, Col: 10},
+			{Kind: TokNumber, Text: "1", Line: 1, Col: 11},
+			{Kind: TokBracketRight, Text: "]", Line: 1, Col: 12},
+		}},
+		{"times", "7:30 17:45:30 7 : 30 7:5 123:45", []Token{
+			{Kind: TokTime, Text: "7:30", Line: 1, Col: 1},
+			{Kind: TokTime, Text: "17:45:30", Line: 1, Col: 6},
+			{Kind: TokNumber, Text: "7", Line: 1, Col: 15},
+			{Kind: TokOperator, Text: ":", Line: 1, Col: 17},
+			{Kind: TokNumber, Text: "30", Line: 1, Col: 19},
+			{Kind: TokNumber, Text: "7", Line: 1, Col: 22},
+			{Kind: TokOperator, Text: ":", Line: 1, Col: 23},
+			{Kind: TokNumber, Text: "5", Line: 1, Col: 24},
+			{Kind: TokNumber, Text: "123", Line: 1, Col: 26},
+			{Kind: TokOperator, Text: ":", Line: 1, Col: 29},
+			{Kind: TokNumber, Text: "45", Line: 1, Col: 30},
+		}},
+		{"lines", "Line Start\nTurn On Fan", []Token{
+			{Kind: TokKeyword, Text: "Line", Line: 1, Col: 1},
+			{Kind: TokIdent, Text: "Start", Line: 1, Col: 6},
+			{Kind: TokNewline, Line: 1, Col: 11},
+			{Kind: TokKeyword, Text: "Turn", Line: 2, Col: 1},
+			{Kind: TokKeyword, Text: "On", Line: 2, Col: 6},
+			{Kind: TokIdent, Text: "Fan", Line: 2, Col: 9},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Scan(tt.input)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLocals(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"numeric", "Numeric Tmp, Count\nTmp = SAT", []string{"count", "tmp"}},
+		{"types", "String S\nDateTime D\nInteger I", []string{"d", "i", "s"}},
+		{"arg", "Arg 1 X\nArg 2 Y\nReturn (X * Y)", []string{"x", "y"}},
+		{"label", "Line Start\nGoto Start", []string{"start"}},
+		{"not declaration", "X = 1 'Numeric Y\nPrint String", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maps.Keys(Locals(Scan(tt.input)))
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package pe reads the Plain English (PE) code of the Continuum
+// programs and functions found in the ByteCode of a dump file.
+package pe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of a token
+type Kind int
+
+const (
+	TokIllegal Kind = iota
+
+	// TokIdent is a name or a path like SAT, SAT.Value or Site\Ctrl1\SAT
+	TokIdent
+	TokKeyword
+	TokNumber
+	// TokTime is a time of day like 7:30 or 17:45:30
+	TokTime
+	TokString
+	TokComment
+	TokOperator
+	TokComma
+	TokParenLeft
+	TokParenRight
+	TokBracketLeft
+	TokBracketRight
+	TokNewline
+)
+
+func (k Kind) String() string {
+	switch k {
+	case TokIllegal:
+		return "illegal"
+	case TokIdent:
+		return "ident"
+	case TokKeyword:
+		return "keyword"
+	case TokNumber:
+		return "number"
+	case TokTime:
+		return "time"
+	case TokString:
+		return "string"
+	case TokComment:
+		return "comment"
+	case TokOperator:
+		return "operator"
+	case TokComma:
+		return ","
+	case TokParenLeft:
+		return "("
+	case TokParenRight:
+		return ")"
+	case TokBracketLeft:
+		return "["
+	case TokBracketRight:
+		return "]"
+	case TokNewline:
+		return "newline"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Token is a token of the code. Line and Col are 1 based and Col is
+// counted in bytes.
+type Token struct {
+	Kind Kind
+	Text string
+	Line int
+	Col  int
+}
+
+func (t Token) String() string {
+	if t.Text == "" || t.Kind == TokNewline {
+		return t.Kind.String()
+	}
+	return fmt.Sprintf("%s(%s)", t.Kind, t.Text)
+}
+
+// Is tests if the token is the keyword, the keyword is not case sensitive
+func (t Token) Is(keyword string) bool {
+	return t.Kind == TokKeyword && strings.EqualFold(t.Text, keyword)
+}
+
+// keywords are the reserved words of the language
+var keywords = map[string]bool{
+	"and":       true,
+	"arg":       true,
+	"case":      true,
+	"datetime":  true,
+	"else":      true,
+	"endif":     true,
+	"endselect": true,
+	"endwhile":  true,
+	"false":     true,
+	"for":       true,
+	"goto":      true,
+	"if":        true,
+	"integer":   true,
+	"is":        true,
+	"line":      true,
+	"next":      true,
+	"not":       true,
+	"numeric":   true,
+	"of":        true,
+	"off":       true,
+	"on":        true,
+	"or":        true,
+	"print":     true,
+	"repeat":    true,
+	"return":    true,
+	"select":    true,
+	"set":       true,
+	"step":      true,
+	"stop":      true,
+	"string":    true,
+	"then":      true,
+	"to":        true,
+	"true":      true,
+	"turn":      true,
+	"until":     true,
+	"wait":      true,
+	"while":     true,
+}
+
+// IsKeyword tests if the word is a keyword, the word is not case sensitive
+func IsKeyword(s string) bool {
+	return keywords[strings.ToLower(s)]
+}
+
+// declarations are the keywords that declare local names
+var declarations = map[string]bool{
+	"numeric":  true,
+	"string":   true,
+	"datetime": true,
+	"integer":  true,
+	"arg":      true,
+}